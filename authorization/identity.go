@@ -0,0 +1,95 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authorization
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Identity is a CI workflow which authenticates keyless submissions with
+// an OIDC identity token.  Repository and owner names can be deleted and
+// registered again by someone else, so the identity includes their IDs.
+type Identity struct {
+	Issuer       string // name given to the token issuer by the Source Spotter instance, such as "github"
+	Repository   string // such as "owner/repo"
+	RepositoryID string // issuer's immutable ID for the repository
+	OwnerID      string // issuer's immutable ID for the repository's owner
+	Workflow     string // path of the workflow file in the repository, such as ".github/workflows/release.yml"
+}
+
+// String returns the identity in the form
+// ISSUER:REPOSITORY:REPOSITORY_ID:OWNER_ID:WORKFLOW
+func (id *Identity) String() string {
+	return id.Issuer + ":" + id.Repository + ":" + id.RepositoryID + ":" + id.OwnerID + ":" + id.Workflow
+}
+
+// ParseIdentity parses an identity in the form returned by String
+func ParseIdentity(s string) (*Identity, error) {
+	fields := strings.SplitN(s, ":", 5)
+	if len(fields) != 5 {
+		return nil, errors.New("identity must be of the form ISSUER:REPOSITORY:REPOSITORY_ID:OWNER_ID:WORKFLOW")
+	}
+	if slices.Contains(fields, "") {
+		return nil, errors.New("identity has an empty component")
+	}
+	return &Identity{Issuer: fields[0], Repository: fields[1], RepositoryID: fields[2], OwnerID: fields[3], Workflow: fields[4]}, nil
+}
+
+// Key returns the key which stands in for the identity wherever an Ed25519
+// public key is expected, such as in the authorizations of a key and the
+// ed25519 parameter of feeds.  No private key corresponds to it.
+func (id *Identity) Key() []byte {
+	hash := sha256.Sum256([]byte("sourcespotter-identity-v1\n" + id.String()))
+	return hash[:]
+}
+
+// IdentityAudience returns the audience which an identity token must have
+// to authenticate payload for domain, which binds the token to the payload
+func IdentityAudience(domain string, payload string) string {
+	return fmt.Sprintf("%s/authorization/%x", domain, sha256.Sum256([]byte(payload)))
+}
+
+// VerifyKeyless verifies a keyless submission by calling verifyToken with
+// its identity token and the audience that the token must have, and checks
+// that the payload is intended for domain and was created within
+// MaxClockSkew of now
+func (s *Submission) VerifyKeyless(domain string, now time.Time, verifyToken func(token, audience string) error) (*Payload, error) {
+	if s.Version != 2 {
+		return nil, fmt.Errorf("submission version %d is not keyless", s.Version)
+	}
+	if s.IdentityToken == "" {
+		return nil, errors.New("keyless submission is missing identity token")
+	}
+	if err := verifyToken(s.IdentityToken, IdentityAudience(domain, s.Payload)); err != nil {
+		return nil, fmt.Errorf("identity token is invalid: %w", err)
+	}
+	return checkPayload(s.Payload, domain, now)
+}
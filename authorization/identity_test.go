@@ -0,0 +1,105 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authorization
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseIdentity(t *testing.T) {
+	id, err := ParseIdentity("github:owner/repo:123:456:.github/workflows/release.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Identity{Issuer: "github", Repository: "owner/repo", RepositoryID: "123", OwnerID: "456", Workflow: ".github/workflows/release.yml"}
+	if *id != want {
+		t.Errorf("ParseIdentity returned %+v, want %+v", *id, want)
+	}
+	if s := id.String(); s != "github:owner/repo:123:456:.github/workflows/release.yml" {
+		t.Errorf("String returned %q", s)
+	}
+
+	for _, input := range []string{"", "github", "github:owner/repo", "github:owner/repo:release.yml", ":owner/repo:123:456:release.yml", "github::123:456:release.yml", "github:owner/repo::456:release.yml", "github:owner/repo:123::release.yml", "github:owner/repo:123:456:"} {
+		if _, err := ParseIdentity(input); err == nil {
+			t.Errorf("ParseIdentity(%q) succeeded", input)
+		}
+	}
+}
+
+func TestIdentityKey(t *testing.T) {
+	a := Identity{Issuer: "github", Repository: "owner/repo", RepositoryID: "123", OwnerID: "456", Workflow: "release.yml"}
+	if len(a.Key()) != 32 {
+		t.Errorf("Key has length %d, want 32", len(a.Key()))
+	}
+	workflow, repository, owner := a, a, a
+	workflow.Workflow = "ci.yml"
+	repository.RepositoryID = "789"
+	owner.OwnerID = "789"
+	for _, b := range []Identity{workflow, repository, owner} {
+		if string(a.Key()) == string(b.Key()) {
+			t.Errorf("%s and %s have the same key", &a, &b)
+		}
+	}
+}
+
+func TestVerifyKeyless(t *testing.T) {
+	p, err := NewPayload("sourcespotter.example", testGoSum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := &Submission{Version: 2, Payload: string(p.Marshal()), IdentityToken: "token"}
+	now := p.Timestamp.Add(time.Minute)
+	verifyToken := func(token, audience string) error {
+		if token != "token" || audience != IdentityAudience("sourcespotter.example", sub.Payload) {
+			return errors.New("wrong token or audience")
+		}
+		return nil
+	}
+
+	verified, err := sub.VerifyKeyless("sourcespotter.example", now, verifyToken)
+	if err != nil {
+		t.Fatalf("VerifyKeyless returned error: %s", err)
+	}
+	if verified.GoSum != testGoSum {
+		t.Errorf("VerifyKeyless returned go.sum %q, want %q", verified.GoSum, testGoSum)
+	}
+	if _, err := sub.Verify("sourcespotter.example", now); err == nil {
+		t.Errorf("Verify succeeded for keyless submission")
+	}
+	if _, err := sub.VerifyKeyless("other.example", now, verifyToken); err == nil {
+		t.Errorf("VerifyKeyless succeeded for wrong domain")
+	}
+	if _, err := sub.VerifyKeyless("sourcespotter.example", now.Add(time.Hour), verifyToken); err == nil {
+		t.Errorf("VerifyKeyless succeeded for stale payload")
+	}
+	tampered := *sub
+	tampered.Payload += "example.com/other v1.0.0 h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n"
+	if _, err := tampered.VerifyKeyless("sourcespotter.example", now, verifyToken); err == nil {
+		t.Errorf("VerifyKeyless succeeded for payload not bound to token")
+	}
+}
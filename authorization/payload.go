@@ -0,0 +1,259 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package authorization contains the signed formats used to authorize module versions with Source Spotter
+package authorization
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Context is the first line of every version 1 payload.  It ensures that
+// signatures over payloads can't be confused with other uses of the key.
+const Context = "sourcespotter-authorization-v1"
+
+// MaxClockSkew is how far a payload's timestamp may differ from the
+// current time
+const MaxClockSkew = 10 * time.Minute
+
+// LogEntryContext is the first line of every entry in the transparency
+// log of submissions, key statements, and policies
+const LogEntryContext = "sourcespotter-authorization-log-v1"
+
+const nonceSize = 16
+
+// Payload is the signed content of an authorization submission
+type Payload struct {
+	Domain    string // Source Spotter instance that the payload is intended for
+	Timestamp time.Time
+	Nonce     []byte
+	GoSum     string
+}
+
+// Submission is the JSON request body accepted by the authorization API.
+// Legacy submissions have a Version of 0, and contain a signature over GoSum
+// instead of Payload.  Keyless submissions have a Version of 2, and are
+// authenticated by an OIDC IdentityToken instead of Ed25519 and Signature.
+type Submission struct {
+	Version       int `json:",omitempty"`
+	Ed25519       []byte
+	Payload       string `json:",omitempty"`
+	GoSum         string `json:",omitempty"`
+	Signature     []byte
+	IdentityToken string `json:",omitempty"`
+}
+
+// NewPayload returns a payload for goSum, intended for domain, with the
+// current time and a random nonce
+func NewPayload(domain string, goSum string) (*Payload, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &Payload{
+		Domain:    domain,
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+		Nonce:     nonce,
+		GoSum:     goSum,
+	}, nil
+}
+
+// Marshal returns the canonical encoding of the payload, which is what gets signed
+func (p *Payload) Marshal() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", Context)
+	fmt.Fprintf(&buf, "domain %s\n", p.Domain)
+	fmt.Fprintf(&buf, "timestamp %s\n", p.Timestamp.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "nonce %s\n", base64.StdEncoding.EncodeToString(p.Nonce))
+	buf.WriteString("\n")
+	buf.WriteString(p.GoSum)
+	return buf.Bytes()
+}
+
+// ParsePayload parses the canonical encoding of a payload
+func ParsePayload(data string) (*Payload, error) {
+	header, goSum, ok := strings.Cut(data, "\n\n")
+	if !ok {
+		return nil, errors.New("payload is missing blank line after header")
+	}
+	lines := strings.Split(header, "\n")
+	if len(lines) != 4 {
+		return nil, errors.New("payload header has wrong number of lines")
+	}
+	if lines[0] != Context {
+		return nil, fmt.Errorf("payload has unrecognized context %q", lines[0])
+	}
+	domain, ok := strings.CutPrefix(lines[1], "domain ")
+	if !ok || domain == "" {
+		return nil, errors.New("payload is missing domain")
+	}
+	timestampString, ok := strings.CutPrefix(lines[2], "timestamp ")
+	if !ok {
+		return nil, errors.New("payload is missing timestamp")
+	}
+	timestamp, err := time.Parse(time.RFC3339, timestampString)
+	if err != nil {
+		return nil, fmt.Errorf("payload has invalid timestamp: %w", err)
+	}
+	nonceString, ok := strings.CutPrefix(lines[3], "nonce ")
+	if !ok {
+		return nil, errors.New("payload is missing nonce")
+	}
+	nonce, err := base64.StdEncoding.DecodeString(nonceString)
+	if err != nil || len(nonce) != nonceSize {
+		return nil, errors.New("payload has invalid nonce")
+	}
+	p := &Payload{
+		Domain:    domain,
+		Timestamp: timestamp,
+		Nonce:     nonce,
+		GoSum:     goSum,
+	}
+	if !bytes.Equal(p.Marshal(), []byte(data)) {
+		return nil, errors.New("payload is not canonically encoded")
+	}
+	return p, nil
+}
+
+// Sign returns a version 1 submission of p, signed by priv
+func Sign(priv ed25519.PrivateKey, p *Payload) *Submission {
+	payload := p.Marshal()
+	return &Submission{
+		Version:   1,
+		Ed25519:   priv.Public().(ed25519.PublicKey),
+		Payload:   string(payload),
+		Signature: ed25519.Sign(priv, payload),
+	}
+}
+
+// SignWith returns a version 1 submission of p, signed by signer, which
+// must have an Ed25519 key.  Use it for keys that aren't in memory, such as
+// keys held by an SSH agent.
+func SignWith(signer crypto.Signer, p *Payload) (*Submission, error) {
+	payload := p.Marshal()
+	pubkey, signature, err := signMessage(signer, payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Submission{
+		Version:   1,
+		Ed25519:   pubkey,
+		Payload:   string(payload),
+		Signature: signature,
+	}, nil
+}
+
+// signMessage signs message with signer's Ed25519 key, returning the
+// public key and signature
+func signMessage(signer crypto.Signer, message []byte) (ed25519.PublicKey, []byte, error) {
+	pubkey, ok := signer.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, nil, errors.New("signer does not have an Ed25519 key")
+	}
+	signature, err := signer.Sign(rand.Reader, message, crypto.Hash(0))
+	if err != nil {
+		return nil, nil, err
+	}
+	return pubkey, signature, nil
+}
+
+// Verify verifies the submission's signature, and checks that it is
+// intended for domain and was signed within MaxClockSkew of now.  For legacy
+// submissions, only the signature is verified, and the returned payload
+// contains only GoSum.  Keyless submissions must be verified with
+// VerifyKeyless instead.
+func (s *Submission) Verify(domain string, now time.Time) (*Payload, error) {
+	if s.Version == 2 {
+		return nil, errors.New("keyless submission has no signature")
+	}
+	if len(s.Ed25519) != ed25519.PublicKeySize {
+		return nil, errors.New("public key has wrong length")
+	}
+	switch s.Version {
+	case 0:
+		if !ed25519.Verify(s.Ed25519, []byte(s.GoSum), s.Signature) {
+			return nil, errors.New("signature validation failed")
+		}
+		return &Payload{GoSum: s.GoSum}, nil
+	case 1:
+		if !verifySignature(s.Ed25519, []byte(s.Payload), s.Signature) {
+			return nil, errors.New("signature validation failed")
+		}
+		return checkPayload(s.Payload, domain, now)
+	default:
+		return nil, fmt.Errorf("unsupported submission version %d", s.Version)
+	}
+}
+
+// checkPayload parses data and checks that it is intended for domain and
+// was created within MaxClockSkew of now
+func checkPayload(data string, domain string, now time.Time) (*Payload, error) {
+	p, err := ParsePayload(data)
+	if err != nil {
+		return nil, err
+	}
+	if p.Domain != domain {
+		return nil, fmt.Errorf("payload is intended for %s, not %s", p.Domain, domain)
+	}
+	if skew := now.Sub(p.Timestamp).Abs(); skew > MaxClockSkew {
+		return nil, fmt.Errorf("payload timestamp %s is too far from the current time", p.Timestamp.Format(time.RFC3339))
+	}
+	return p, nil
+}
+
+// SignedData returns the data covered by the submission's signature or
+// identity token: the payload, or for legacy submissions, the go.sum file
+func (s *Submission) SignedData() string {
+	if s.Version == 0 {
+		return s.GoSum
+	}
+	return s.Payload
+}
+
+// LogEntry returns the encoding of the submission, received at receivedAt,
+// which is hashed into the transparency log of submissions
+func (s *Submission) LogEntry(receivedAt time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", LogEntryContext)
+	fmt.Fprintf(&buf, "received %s\n", receivedAt.UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&buf, "version %d\n", s.Version)
+	if s.Version == 2 {
+		fmt.Fprintf(&buf, "identity_token %s\n", s.IdentityToken)
+	} else {
+		fmt.Fprintf(&buf, "ed25519 %s\n", base64.StdEncoding.EncodeToString(s.Ed25519))
+		fmt.Fprintf(&buf, "signature %s\n", base64.StdEncoding.EncodeToString(s.Signature))
+	}
+	buf.WriteString("\n")
+	buf.WriteString(s.SignedData())
+	return buf.Bytes()
+}
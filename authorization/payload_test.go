@@ -0,0 +1,126 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authorization
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testGoSum = "example.com/mod v1.0.0 h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n"
+
+func TestPayloadRoundTrip(t *testing.T) {
+	p, err := NewPayload("sourcespotter.example", testGoSum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePayload(string(p.Marshal()))
+	if err != nil {
+		t.Fatalf("ParsePayload returned error: %s", err)
+	}
+	if parsed.Domain != p.Domain || !parsed.Timestamp.Equal(p.Timestamp) || string(parsed.Nonce) != string(p.Nonce) || parsed.GoSum != p.GoSum {
+		t.Errorf("ParsePayload returned %+v, want %+v", parsed, p)
+	}
+}
+
+func TestParsePayloadErrors(t *testing.T) {
+	valid := Context + "\ndomain sourcespotter.example\ntimestamp 2026-01-02T03:04:05Z\nnonce AAAAAAAAAAAAAAAAAAAAAA==\n\n" + testGoSum
+	if _, err := ParsePayload(valid); err != nil {
+		t.Fatalf("ParsePayload(valid) returned error: %s", err)
+	}
+	tests := []string{
+		"",
+		strings.Replace(valid, Context, "sourcespotter-authorization-v2", 1),
+		strings.Replace(valid, "domain sourcespotter.example\n", "", 1),
+		strings.Replace(valid, "2026-01-02T03:04:05Z", "2026-01-02T04:04:05+01:00", 1),
+		strings.Replace(valid, "AAAAAAAAAAAAAAAAAAAAAA==", "AAAA", 1),
+		strings.Replace(valid, "\n\n", "\n", 1),
+	}
+	for _, input := range tests {
+		if _, err := ParsePayload(input); err == nil {
+			t.Errorf("ParsePayload(%q) succeeded, want error", input)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	p, err := NewPayload("sourcespotter.example", testGoSum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := Sign(priv, p)
+
+	if got, err := sub.Verify("sourcespotter.example", now); err != nil {
+		t.Errorf("Verify returned error: %s", err)
+	} else if got.GoSum != testGoSum {
+		t.Errorf("Verify returned go.sum %q, want %q", got.GoSum, testGoSum)
+	}
+	if _, err := sub.Verify("other.example", now); err == nil {
+		t.Errorf("Verify succeeded for wrong domain")
+	}
+	if _, err := sub.Verify("sourcespotter.example", now.Add(time.Hour)); err == nil {
+		t.Errorf("Verify succeeded for stale timestamp")
+	}
+
+	tampered := *sub
+	tampered.Payload = strings.Replace(sub.Payload, "v1.0.0", "v1.0.1", 1)
+	if _, err := tampered.Verify("sourcespotter.example", now); err == nil {
+		t.Errorf("Verify succeeded for tampered payload")
+	}
+
+	legacy := &Submission{Ed25519: sub.Ed25519, GoSum: testGoSum, Signature: ed25519.Sign(priv, []byte(testGoSum))}
+	if got, err := legacy.Verify("sourcespotter.example", now); err != nil {
+		t.Errorf("Verify returned error for legacy submission: %s", err)
+	} else if got.GoSum != testGoSum {
+		t.Errorf("Verify returned go.sum %q for legacy submission, want %q", got.GoSum, testGoSum)
+	}
+
+	// a version 1 signature must not validate as a legacy submission, and vice versa
+	confused := &Submission{Ed25519: sub.Ed25519, GoSum: sub.Payload, Signature: legacy.Signature}
+	if _, err := confused.Verify("sourcespotter.example", now); err == nil {
+		t.Errorf("Verify succeeded for confused legacy submission")
+	}
+}
+
+func TestLogEntry(t *testing.T) {
+	sub := &Submission{Version: 1, Ed25519: make([]byte, 32), Payload: "payload\n", Signature: make([]byte, 64)}
+	receivedAt := time.Date(2026, 1, 2, 3, 4, 5, 678000, time.FixedZone("", 3600))
+	want := LogEntryContext + "\nreceived 2026-01-02T02:04:05.000678Z\nversion 1\ned25519 " + strings.Repeat("A", 43) + "=\nsignature " + strings.Repeat("A", 86) + "==\n\npayload\n"
+	if got := string(sub.LogEntry(receivedAt)); got != want {
+		t.Errorf("LogEntry = %q, want %q", got, want)
+	}
+	legacy := &Submission{Ed25519: sub.Ed25519, GoSum: testGoSum, Signature: sub.Signature}
+	if got := legacy.LogEntry(receivedAt); !strings.HasSuffix(string(got), "\n\n"+testGoSum) || !strings.Contains(string(got), "\nversion 0\n") {
+		t.Errorf("LogEntry of legacy submission = %q", got)
+	}
+}
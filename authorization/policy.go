@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authorization
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// PolicyContext is the first line of every policy
+const PolicyContext = "sourcespotter-policy-v1"
+
+// Policy requires a module version to be authorized by Threshold of Keys
+// before it is considered authorized
+type Policy struct {
+	Domain    string
+	Timestamp time.Time
+	Module    string // module path, or module path prefix ending in a slash
+	Threshold int
+	Keys      []ed25519.PublicKey
+}
+
+// PolicySignature is a signature over a policy by one of its keys
+type PolicySignature struct {
+	Ed25519   []byte
+	Signature []byte
+}
+
+// SignedPolicy is the JSON request body accepted by the policies API
+type SignedPolicy struct {
+	Policy     string
+	Signatures []PolicySignature
+}
+
+func (p *Policy) Marshal() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", PolicyContext)
+	fmt.Fprintf(&buf, "domain %s\n", p.Domain)
+	fmt.Fprintf(&buf, "timestamp %s\n", p.Timestamp.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "module %s\n", p.Module)
+	fmt.Fprintf(&buf, "threshold %d\n", p.Threshold)
+	for _, key := range p.Keys {
+		fmt.Fprintf(&buf, "key %s\n", encodeKey(key))
+	}
+	return buf.Bytes()
+}
+
+// PolicyID returns the identifier of the encoded policy, which is the
+// hex-encoded SHA-256 hash of its encoding
+func PolicyID(policy string) string {
+	hash := sha256.Sum256([]byte(policy))
+	return hex.EncodeToString(hash[:])
+}
+
+// ParsePolicy parses the canonical encoding of a policy
+func ParsePolicy(data string) (*Policy, error) {
+	lines, ok := strings.CutSuffix(data, "\n")
+	if !ok {
+		return nil, errors.New("policy does not end with newline")
+	}
+	split := strings.Split(lines, "\n")
+	if len(split) < 6 {
+		return nil, errors.New("policy has too few lines")
+	}
+	_, values, err := statementFields(strings.Join(split[:5], "\n")+"\n", "domain", "timestamp", "module", "threshold")
+	if err != nil {
+		return nil, err
+	}
+	if split[0] != PolicyContext {
+		return nil, fmt.Errorf("policy has unrecognized context %q", split[0])
+	}
+	p := &Policy{Domain: values[0], Module: values[2]}
+	if p.Timestamp, err = time.Parse(time.RFC3339, values[1]); err != nil {
+		return nil, fmt.Errorf("policy has invalid timestamp: %w", err)
+	}
+	if err := module.CheckImportPath(strings.TrimSuffix(p.Module, "/")); err != nil {
+		return nil, fmt.Errorf("policy has invalid module: %w", err)
+	}
+	if p.Threshold, err = strconv.Atoi(values[3]); err != nil {
+		return nil, fmt.Errorf("policy has invalid threshold: %w", err)
+	}
+	for _, line := range split[5:] {
+		value, ok := strings.CutPrefix(line, "key ")
+		if !ok {
+			return nil, errors.New("policy has invalid key line")
+		}
+		key, err := parseKey(value)
+		if err != nil {
+			return nil, err
+		}
+		for _, existing := range p.Keys {
+			if existing.Equal(key) {
+				return nil, errors.New("policy lists the same key more than once")
+			}
+		}
+		p.Keys = append(p.Keys, key)
+	}
+	if p.Threshold < 1 || p.Threshold > len(p.Keys) {
+		return nil, fmt.Errorf("policy threshold must be between 1 and %d", len(p.Keys))
+	}
+	if !bytes.Equal(p.Marshal(), []byte(data)) {
+		return nil, errors.New("policy is not canonically encoded")
+	}
+	return p, nil
+}
+
+// HasKey reports whether key is one of the policy's keys
+func (p *Policy) HasKey(key []byte) bool {
+	for _, k := range p.Keys {
+		if k.Equal(ed25519.PublicKey(key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SignPolicy returns a signature over p by priv, which must be one of its keys
+func SignPolicy(priv ed25519.PrivateKey, p *Policy) PolicySignature {
+	return PolicySignature{
+		Ed25519:   priv.Public().(ed25519.PublicKey),
+		Signature: ed25519.Sign(priv, p.Marshal()),
+	}
+}
+
+// LogEntry returns the encoding of the policy, received at receivedAt,
+// which is hashed into the transparency log.  Its signatures are listed in
+// order of key, once per key.
+func (s *SignedPolicy) LogEntry(receivedAt time.Time) []byte {
+	sigs := slices.Clone(s.Signatures)
+	slices.SortStableFunc(sigs, func(a, b PolicySignature) int { return bytes.Compare(a.Ed25519, b.Ed25519) })
+	sigs = slices.CompactFunc(sigs, func(a, b PolicySignature) bool { return bytes.Equal(a.Ed25519, b.Ed25519) })
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", LogEntryContext)
+	fmt.Fprintf(&buf, "received %s\n", receivedAt.UTC().Format(time.RFC3339Nano))
+	for _, sig := range sigs {
+		fmt.Fprintf(&buf, "ed25519 %s\n", encodeKey(sig.Ed25519))
+		fmt.Fprintf(&buf, "signature %s\n", encodeKey(sig.Signature))
+	}
+	buf.WriteString("\n")
+	buf.WriteString(s.Policy)
+	return buf.Bytes()
+}
+
+// Verify parses the policy, checks that it is intended for domain and was
+// signed within MaxClockSkew of now, and checks that it has valid signatures
+// from at least Threshold of its keys.
+func (s *SignedPolicy) Verify(domain string, now time.Time) (*Policy, error) {
+	p, err := ParsePolicy(s.Policy)
+	if err != nil {
+		return nil, err
+	}
+	if p.Domain != domain {
+		return nil, fmt.Errorf("policy is intended for %s, not %s", p.Domain, domain)
+	}
+	if now.Sub(p.Timestamp).Abs() > MaxClockSkew {
+		return nil, fmt.Errorf("policy timestamp %s is too far from the current time", p.Timestamp.Format(time.RFC3339))
+	}
+	signers := make(map[string]bool)
+	for _, sig := range s.Signatures {
+		if !p.HasKey(sig.Ed25519) {
+			return nil, fmt.Errorf("policy is signed by %s, which is not one of its keys", encodeKey(sig.Ed25519))
+		}
+		if !verifySignature(sig.Ed25519, []byte(s.Policy), sig.Signature) {
+			return nil, fmt.Errorf("signature by %s failed validation", encodeKey(sig.Ed25519))
+		}
+		signers[string(sig.Ed25519)] = true
+	}
+	if len(signers) < p.Threshold {
+		return nil, fmt.Errorf("policy is signed by %d of its keys, but requires %d", len(signers), p.Threshold)
+	}
+	return p, nil
+}
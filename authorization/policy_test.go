@@ -0,0 +1,126 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authorization
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+	"time"
+)
+
+func TestPolicy(t *testing.T) {
+	var pubs []ed25519.PublicKey
+	var privs []ed25519.PrivateKey
+	for range 3 {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, pub)
+		privs = append(privs, priv)
+	}
+	_, outsider, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	const domain = "sourcespotter.example"
+
+	policy := &Policy{Domain: domain, Timestamp: now, Module: "example.com/", Threshold: 2, Keys: pubs}
+	encoded := string(policy.Marshal())
+	parsed, err := ParsePolicy(encoded)
+	if err != nil {
+		t.Fatalf("ParsePolicy returned error: %s", err)
+	}
+	if parsed.Module != policy.Module || parsed.Threshold != 2 || len(parsed.Keys) != 3 || !parsed.Keys[2].Equal(pubs[2]) {
+		t.Errorf("ParsePolicy returned %#v", parsed)
+	}
+	if PolicyID(encoded) != PolicyID(string(parsed.Marshal())) || len(PolicyID(encoded)) != 64 {
+		t.Errorf("PolicyID is not stable: %s", PolicyID(encoded))
+	}
+
+	tests := []struct {
+		name       string
+		signatures []PolicySignature
+		ok         bool
+	}{
+		{"threshold", []PolicySignature{SignPolicy(privs[0], policy), SignPolicy(privs[2], policy)}, true},
+		{"all", []PolicySignature{SignPolicy(privs[0], policy), SignPolicy(privs[1], policy), SignPolicy(privs[2], policy)}, true},
+		{"too few", []PolicySignature{SignPolicy(privs[1], policy)}, false},
+		{"duplicate", []PolicySignature{SignPolicy(privs[1], policy), SignPolicy(privs[1], policy)}, false},
+		{"outsider", []PolicySignature{SignPolicy(privs[0], policy), SignPolicy(privs[1], policy), SignPolicy(outsider, policy)}, false},
+	}
+	for _, test := range tests {
+		signed := &SignedPolicy{Policy: encoded, Signatures: test.signatures}
+		if _, err := signed.Verify(domain, now); (err == nil) != test.ok {
+			t.Errorf("%s: Verify returned %v", test.name, err)
+		}
+	}
+
+	signed := &SignedPolicy{Policy: encoded, Signatures: []PolicySignature{SignPolicy(privs[0], policy), SignPolicy(privs[1], policy)}}
+	if _, err := signed.Verify("other.example", now); err == nil {
+		t.Errorf("Verify succeeded for policy intended for other domain")
+	}
+	if _, err := signed.Verify(domain, now.Add(time.Hour)); err == nil {
+		t.Errorf("Verify succeeded for policy with stale timestamp")
+	}
+	forged := signed.Signatures[1]
+	forged.Signature = append([]byte(nil), forged.Signature...)
+	forged.Signature[0] ^= 1
+	if _, err := (&SignedPolicy{Policy: encoded, Signatures: []PolicySignature{signed.Signatures[0], forged}}).Verify(domain, now); err == nil {
+		t.Errorf("Verify succeeded for policy with invalid signature")
+	}
+
+	invalid := []*Policy{
+		{Domain: domain, Timestamp: now, Module: "example.com/", Threshold: 0, Keys: pubs},
+		{Domain: domain, Timestamp: now, Module: "example.com/", Threshold: 4, Keys: pubs},
+		{Domain: domain, Timestamp: now, Module: "example.com/", Threshold: 1, Keys: []ed25519.PublicKey{pubs[0], pubs[0]}},
+		{Domain: domain, Timestamp: now, Module: "example.com/", Threshold: 1},
+		{Domain: domain, Timestamp: now, Module: "not a module", Threshold: 1, Keys: pubs},
+	}
+	for _, p := range invalid {
+		if _, err := ParsePolicy(string(p.Marshal())); err == nil {
+			t.Errorf("ParsePolicy succeeded for invalid policy %q", p.Marshal())
+		}
+	}
+	if _, err := ParsePolicy(encoded + "key " + encodeKey(pubs[0]) + "\n"); err == nil {
+		t.Errorf("ParsePolicy succeeded for policy with repeated key")
+	}
+
+	// The log entry doesn't depend on the order or repetition of signatures
+	sig0, sig1 := SignPolicy(privs[0], policy), SignPolicy(privs[1], policy)
+	if bytes.Compare(sig0.Ed25519, sig1.Ed25519) > 0 {
+		sig0, sig1 = sig1, sig0
+	}
+	entry := (&SignedPolicy{Policy: encoded, Signatures: []PolicySignature{sig1, sig0, sig1}}).LogEntry(now)
+	want := LogEntryContext + "\nreceived " + now.Format(time.RFC3339Nano) +
+		"\ned25519 " + encodeKey(sig0.Ed25519) + "\nsignature " + encodeKey(sig0.Signature) +
+		"\ned25519 " + encodeKey(sig1.Ed25519) + "\nsignature " + encodeKey(sig1.Signature) + "\n\n" + encoded
+	if string(entry) != want {
+		t.Errorf("LogEntry = %q, want %q", entry, want)
+	}
+}
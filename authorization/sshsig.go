@@ -0,0 +1,141 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authorization
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+// SSHNamespace is the namespace of SSHSIG signatures over payloads,
+// statements, and policies.  Such signatures can be made with
+// `ssh-keygen -Y sign -n sourcespotter`; the base64 between the armor lines
+// of the output is the value of the Signature field.
+const SSHNamespace = "sourcespotter"
+
+const (
+	sshsigMagic   = "SSHSIG"
+	sshsigVersion = 1
+	sshEd25519    = "ssh-ed25519"
+)
+
+// verifySignature reports whether sig is a valid signature of message by
+// pubkey.  sig is either a raw Ed25519 signature or an SSHSIG envelope.
+func verifySignature(pubkey ed25519.PublicKey, message, sig []byte) bool {
+	if len(pubkey) != ed25519.PublicKeySize {
+		return false
+	}
+	if len(sig) == ed25519.SignatureSize {
+		return ed25519.Verify(pubkey, message, sig)
+	}
+	if !bytes.HasPrefix(sig, []byte(sshsigMagic)) {
+		return false
+	}
+	signedData, rawSig, err := parseSSHSIG(pubkey, message, sig)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(pubkey, signedData, rawSig)
+}
+
+// parseSSHSIG parses an SSHSIG envelope, as described in OpenSSH's
+// PROTOCOL.sshsig, for an Ed25519 signature by pubkey in SSHNamespace.  It
+// returns the data which was signed and the raw Ed25519 signature.
+func parseSSHSIG(pubkey ed25519.PublicKey, message, envelope []byte) ([]byte, []byte, error) {
+	r := sshReader(envelope[len(sshsigMagic):])
+	version, ok := r.uint32()
+	if !ok || version != sshsigVersion {
+		return nil, nil, errors.New("unsupported SSHSIG version")
+	}
+	publicKey, ok1 := r.string()
+	namespace, ok2 := r.string()
+	reserved, ok3 := r.string()
+	hashAlgorithm, ok4 := r.string()
+	signature, ok5 := r.string()
+	if !(ok1 && ok2 && ok3 && ok4 && ok5) || len(r) != 0 {
+		return nil, nil, errors.New("malformed SSHSIG envelope")
+	}
+	if !bytes.Equal(publicKey, sshString([]byte(sshEd25519), pubkey)) {
+		return nil, nil, errors.New("SSHSIG envelope is for a different key")
+	}
+	if string(namespace) != SSHNamespace {
+		return nil, nil, errors.New("SSHSIG envelope has wrong namespace")
+	}
+	var h hash.Hash
+	switch string(hashAlgorithm) {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, nil, errors.New("SSHSIG envelope has unsupported hash algorithm")
+	}
+	sigReader := sshReader(signature)
+	format, ok1 := sigReader.string()
+	rawSig, ok2 := sigReader.string()
+	if !ok1 || !ok2 || len(sigReader) != 0 || string(format) != sshEd25519 {
+		return nil, nil, errors.New("SSHSIG envelope does not contain an Ed25519 signature")
+	}
+	h.Write(message)
+	signedData := append([]byte(sshsigMagic), sshString(namespace, reserved, hashAlgorithm, h.Sum(nil))...)
+	return signedData, rawSig, nil
+}
+
+// sshString encodes each of the values as an SSH wire format string
+func sshString(values ...[]byte) []byte {
+	var out []byte
+	for _, value := range values {
+		out = binary.BigEndian.AppendUint32(out, uint32(len(value)))
+		out = append(out, value...)
+	}
+	return out
+}
+
+type sshReader []byte
+
+func (r *sshReader) uint32() (uint32, bool) {
+	if len(*r) < 4 {
+		return 0, false
+	}
+	value := binary.BigEndian.Uint32(*r)
+	*r = (*r)[4:]
+	return value, true
+}
+
+func (r *sshReader) string() ([]byte, bool) {
+	length, ok := r.uint32()
+	if !ok || uint64(len(*r)) < uint64(length) {
+		return nil, false
+	}
+	value := (*r)[:length]
+	*r = (*r)[length:]
+	return value, true
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authorization
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// testSSHSIG returns an SSHSIG envelope like the one produced by
+// `ssh-keygen -Y sign -n namespace`
+func testSSHSIG(priv ed25519.PrivateKey, namespace string, message []byte) []byte {
+	digest := sha512.Sum512(message)
+	signedData := append([]byte(sshsigMagic), sshString([]byte(namespace), nil, []byte("sha512"), digest[:])...)
+	envelope := binary.BigEndian.AppendUint32([]byte(sshsigMagic), sshsigVersion)
+	envelope = append(envelope, sshString(
+		sshString([]byte(sshEd25519), priv.Public().(ed25519.PublicKey)),
+		[]byte(namespace),
+		nil,
+		[]byte("sha512"),
+		sshString([]byte(sshEd25519), ed25519.Sign(priv, signedData)),
+	)...)
+	return envelope
+}
+
+func TestVerifySignature(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	message := []byte("message")
+	envelope := testSSHSIG(priv, SSHNamespace, message)
+
+	tests := []struct {
+		name   string
+		pubkey ed25519.PublicKey
+		sig    []byte
+		ok     bool
+	}{
+		{"raw", pub, ed25519.Sign(priv, message), true},
+		{"raw wrong key", otherPub, ed25519.Sign(priv, message), false},
+		{"sshsig", pub, envelope, true},
+		{"sshsig wrong key", otherPub, envelope, false},
+		{"sshsig signed by other key", pub, bytes.Replace(testSSHSIG(otherPriv, SSHNamespace, message), otherPub, pub, 1), false},
+		{"sshsig wrong namespace", pub, testSSHSIG(priv, "file", message), false},
+		{"sshsig wrong message", pub, testSSHSIG(priv, SSHNamespace, []byte("other")), false},
+		{"sshsig truncated", pub, envelope[:len(envelope)-1], false},
+		{"sshsig trailing data", pub, append(envelope[:len(envelope):len(envelope)], 0), false},
+		{"empty", pub, nil, false},
+	}
+	for _, test := range tests {
+		if ok := verifySignature(test.pubkey, message, test.sig); ok != test.ok {
+			t.Errorf("%s: verifySignature returned %v, want %v", test.name, ok, test.ok)
+		}
+	}
+}
+
+func TestSignWith(t *testing.T) {
+	const domain = "sourcespotter.example"
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	p, err := NewPayload(domain, testGoSum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := SignWith(priv, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Verify(domain, time.Now()); err != nil {
+		t.Errorf("Verify returned error: %s", err)
+	}
+
+	s.Signature = testSSHSIG(priv, SSHNamespace, []byte(s.Payload))
+	if _, err := s.Verify(domain, time.Now()); err != nil {
+		t.Errorf("Verify of SSHSIG envelope returned error: %s", err)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authorization
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// RotationContext is the first line of every rotation statement
+	RotationContext = "sourcespotter-key-rotation-v1"
+	// RevocationContext is the first line of every revocation statement
+	RevocationContext = "sourcespotter-key-revocation-v1"
+)
+
+// Rotation is a statement, signed by both Old and New, that New replaces Old
+type Rotation struct {
+	Domain    string
+	Timestamp time.Time
+	Old       ed25519.PublicKey
+	New       ed25519.PublicKey
+}
+
+// Revocation is a statement that Key must no longer be trusted.  Versions
+// authorized by Key at or after Since are no longer considered authorized.
+type Revocation struct {
+	Domain    string
+	Timestamp time.Time
+	Key       ed25519.PublicKey
+	Since     time.Time
+}
+
+// SignedStatement is the JSON request body accepted by the key statements API
+type SignedStatement struct {
+	Ed25519      []byte // key which signed Statement
+	Statement    string
+	Signature    []byte
+	NewSignature []byte `json:",omitempty"` // for rotations, signature over Statement by the new key
+}
+
+func (r *Rotation) Marshal() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", RotationContext)
+	fmt.Fprintf(&buf, "domain %s\n", r.Domain)
+	fmt.Fprintf(&buf, "timestamp %s\n", r.Timestamp.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "old %s\n", encodeKey(r.Old))
+	fmt.Fprintf(&buf, "new %s\n", encodeKey(r.New))
+	return buf.Bytes()
+}
+
+func (r *Revocation) Marshal() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", RevocationContext)
+	fmt.Fprintf(&buf, "domain %s\n", r.Domain)
+	fmt.Fprintf(&buf, "timestamp %s\n", r.Timestamp.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "key %s\n", encodeKey(r.Key))
+	fmt.Fprintf(&buf, "since %s\n", r.Since.UTC().Format(time.RFC3339))
+	return buf.Bytes()
+}
+
+// statementFields splits a statement into its context line and the values
+// of the remaining lines, which must have the given keys in order
+func statementFields(data string, keys ...string) (string, []string, error) {
+	lines, ok := strings.CutSuffix(data, "\n")
+	if !ok {
+		return "", nil, errors.New("statement does not end with newline")
+	}
+	split := strings.Split(lines, "\n")
+	if len(split) != len(keys)+1 {
+		return "", nil, errors.New("statement has wrong number of lines")
+	}
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		value, ok := strings.CutPrefix(split[i+1], key+" ")
+		if !ok || value == "" {
+			return "", nil, fmt.Errorf("statement is missing %s", key)
+		}
+		values[i] = value
+	}
+	return split[0], values, nil
+}
+
+func encodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func parseKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 key %q", s)
+	}
+	return key, nil
+}
+
+// ParseStatement parses the canonical encoding of a *Rotation or *Revocation
+func ParseStatement(data string) (any, error) {
+	context, _, _ := strings.Cut(data, "\n")
+	var statement interface{ Marshal() []byte }
+	switch context {
+	case RotationContext:
+		_, values, err := statementFields(data, "domain", "timestamp", "old", "new")
+		if err != nil {
+			return nil, err
+		}
+		r := &Rotation{Domain: values[0]}
+		if r.Timestamp, err = time.Parse(time.RFC3339, values[1]); err != nil {
+			return nil, fmt.Errorf("statement has invalid timestamp: %w", err)
+		}
+		if r.Old, err = parseKey(values[2]); err != nil {
+			return nil, err
+		}
+		if r.New, err = parseKey(values[3]); err != nil {
+			return nil, err
+		}
+		if r.Old.Equal(r.New) {
+			return nil, errors.New("old and new keys are the same")
+		}
+		statement = r
+	case RevocationContext:
+		_, values, err := statementFields(data, "domain", "timestamp", "key", "since")
+		if err != nil {
+			return nil, err
+		}
+		r := &Revocation{Domain: values[0]}
+		if r.Timestamp, err = time.Parse(time.RFC3339, values[1]); err != nil {
+			return nil, fmt.Errorf("statement has invalid timestamp: %w", err)
+		}
+		if r.Key, err = parseKey(values[2]); err != nil {
+			return nil, err
+		}
+		if r.Since, err = time.Parse(time.RFC3339, values[3]); err != nil {
+			return nil, fmt.Errorf("statement has invalid since time: %w", err)
+		}
+		if r.Since.After(r.Timestamp) {
+			return nil, errors.New("revocation cannot take effect after it is made")
+		}
+		statement = r
+	default:
+		return nil, fmt.Errorf("statement has unrecognized context %q", context)
+	}
+	if !bytes.Equal(statement.Marshal(), []byte(data)) {
+		return nil, errors.New("statement is not canonically encoded")
+	}
+	return statement, nil
+}
+
+// LogEntry returns the encoding of the statement, received at receivedAt,
+// which is hashed into the transparency log.  Unlike a submission's entry,
+// it has no version line, and the statement's context line identifies it.
+func (s *SignedStatement) LogEntry(receivedAt time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", LogEntryContext)
+	fmt.Fprintf(&buf, "received %s\n", receivedAt.UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&buf, "ed25519 %s\n", encodeKey(s.Ed25519))
+	fmt.Fprintf(&buf, "signature %s\n", encodeKey(s.Signature))
+	if s.NewSignature != nil {
+		fmt.Fprintf(&buf, "new_signature %s\n", encodeKey(s.NewSignature))
+	}
+	buf.WriteString("\n")
+	buf.WriteString(s.Statement)
+	return buf.Bytes()
+}
+
+// SignStatement signs a *Rotation or *Revocation with priv
+func SignStatement(priv ed25519.PrivateKey, statement interface{ Marshal() []byte }) *SignedStatement {
+	data := statement.Marshal()
+	return &SignedStatement{
+		Ed25519:   priv.Public().(ed25519.PublicKey),
+		Statement: string(data),
+		Signature: ed25519.Sign(priv, data),
+	}
+}
+
+// SignRotation signs r with both its old key, oldPriv, and its new key, newPriv
+func SignRotation(oldPriv, newPriv ed25519.PrivateKey, r *Rotation) *SignedStatement {
+	s := SignStatement(oldPriv, r)
+	s.NewSignature = ed25519.Sign(newPriv, []byte(s.Statement))
+	return s
+}
+
+// SignStatementWith signs a *Rotation or *Revocation with signer, which must
+// have an Ed25519 key
+func SignStatementWith(signer crypto.Signer, statement interface{ Marshal() []byte }) (*SignedStatement, error) {
+	data := statement.Marshal()
+	pubkey, signature, err := signMessage(signer, data)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedStatement{
+		Ed25519:   pubkey,
+		Statement: string(data),
+		Signature: signature,
+	}, nil
+}
+
+// Verify verifies the statement's signature, and checks that it is intended
+// for domain and was signed within MaxClockSkew of now.  It returns a
+// *Rotation or *Revocation.  Rotations must be signed by the old key and
+// countersigned by the new key, so that no one can claim another's key as
+// the successor of their own; the caller is responsible for checking who
+// signed a revocation.
+func (s *SignedStatement) Verify(domain string, now time.Time) (any, error) {
+	if len(s.Ed25519) != ed25519.PublicKeySize {
+		return nil, errors.New("public key has wrong length")
+	}
+	if !verifySignature(s.Ed25519, []byte(s.Statement), s.Signature) {
+		return nil, errors.New("signature validation failed")
+	}
+	statement, err := ParseStatement(s.Statement)
+	if err != nil {
+		return nil, err
+	}
+	var statementDomain string
+	var timestamp time.Time
+	switch st := statement.(type) {
+	case *Rotation:
+		if !st.Old.Equal(ed25519.PublicKey(s.Ed25519)) {
+			return nil, errors.New("rotation must be signed by the old key")
+		}
+		if !verifySignature(st.New, []byte(s.Statement), s.NewSignature) {
+			return nil, errors.New("rotation must also be signed by the new key")
+		}
+		statementDomain, timestamp = st.Domain, st.Timestamp
+	case *Revocation:
+		statementDomain, timestamp = st.Domain, st.Timestamp
+	}
+	if statementDomain != domain {
+		return nil, fmt.Errorf("statement is intended for %s, not %s", statementDomain, domain)
+	}
+	if now.Sub(timestamp).Abs() > MaxClockSkew {
+		return nil, fmt.Errorf("statement timestamp %s is too far from the current time", timestamp.Format(time.RFC3339))
+	}
+	return statement, nil
+}
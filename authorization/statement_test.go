@@ -0,0 +1,114 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authorization
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatements(t *testing.T) {
+	oldPub, oldPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPub, newPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	const domain = "sourcespotter.example"
+
+	rotation := &Rotation{Domain: domain, Timestamp: now, Old: oldPub, New: newPub}
+	if st, err := SignRotation(oldPriv, newPriv, rotation).Verify(domain, now); err != nil {
+		t.Errorf("Verify(rotation) returned error: %s", err)
+	} else if r, ok := st.(*Rotation); !ok || !r.New.Equal(newPub) || !r.Old.Equal(oldPub) {
+		t.Errorf("Verify(rotation) returned %#v", st)
+	}
+	if _, err := SignStatement(newPriv, rotation).Verify(domain, now); err == nil {
+		t.Errorf("Verify succeeded for rotation signed by new key")
+	}
+	if _, err := SignStatement(oldPriv, rotation).Verify(domain, now); err == nil {
+		t.Errorf("Verify succeeded for rotation which was not countersigned by new key")
+	}
+	if _, err := SignRotation(oldPriv, newPriv, rotation).Verify("other.example", now); err == nil {
+		t.Errorf("Verify succeeded for rotation intended for other domain")
+	}
+	if _, err := SignRotation(oldPriv, newPriv, rotation).Verify(domain, now.Add(-time.Hour)); err == nil {
+		t.Errorf("Verify succeeded for rotation with stale timestamp")
+	}
+
+	// An attacker must not be able to claim someone else's key as the
+	// successor of their own, and thereby have their authorizations counted
+	// as the victim's
+	attackerPub, attackerPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := &Rotation{Domain: domain, Timestamp: now, Old: attackerPub, New: oldPub}
+	if _, err := SignStatement(attackerPriv, forged).Verify(domain, now); err == nil {
+		t.Errorf("Verify succeeded for forged rotation to victim's key")
+	}
+	if _, err := SignRotation(attackerPriv, attackerPriv, forged).Verify(domain, now); err == nil {
+		t.Errorf("Verify succeeded for forged rotation countersigned by attacker's key")
+	}
+
+	revocation := &Revocation{Domain: domain, Timestamp: now, Key: oldPub, Since: now.Add(-24 * time.Hour)}
+	if st, err := SignStatement(newPriv, revocation).Verify(domain, now); err != nil {
+		t.Errorf("Verify(revocation) returned error: %s", err)
+	} else if r, ok := st.(*Revocation); !ok || !r.Key.Equal(oldPub) || !r.Since.Equal(revocation.Since) {
+		t.Errorf("Verify(revocation) returned %#v", st)
+	}
+
+	future := &Revocation{Domain: domain, Timestamp: now, Key: oldPub, Since: now.Add(time.Hour)}
+	if _, err := ParseStatement(string(future.Marshal())); err == nil {
+		t.Errorf("ParseStatement succeeded for revocation that takes effect in the future")
+	}
+
+	// An authorization payload must not parse as a statement, and vice versa
+	payload := &Payload{Domain: domain, Timestamp: now, Nonce: make([]byte, nonceSize), GoSum: testGoSum}
+	if _, err := ParseStatement(string(payload.Marshal())); err == nil {
+		t.Errorf("ParseStatement succeeded for authorization payload")
+	}
+	if _, err := ParsePayload(string(rotation.Marshal())); err == nil {
+		t.Errorf("ParsePayload succeeded for rotation statement")
+	}
+	if _, err := ParseStatement(strings.TrimSuffix(string(rotation.Marshal()), "\n")); err == nil {
+		t.Errorf("ParseStatement succeeded for statement without trailing newline")
+	}
+
+	signed := SignRotation(oldPriv, newPriv, rotation)
+	entry := string(signed.LogEntry(now))
+	want := LogEntryContext + "\nreceived " + now.Format(time.RFC3339Nano) + "\ned25519 " + encodeKey(oldPub) + "\nsignature " + encodeKey(signed.Signature) + "\nnew_signature " + encodeKey(signed.NewSignature) + "\n\n" + signed.Statement
+	if entry != want {
+		t.Errorf("LogEntry of rotation = %q, want %q", entry, want)
+	}
+	if entry := string(SignStatement(newPriv, revocation).LogEntry(now)); strings.Contains(entry, "new_signature") || strings.Contains(entry, "\nversion ") {
+		t.Errorf("LogEntry of revocation = %q", entry)
+	}
+}
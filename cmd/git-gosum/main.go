@@ -23,39 +23,103 @@
 // sale, use or other dealings in this Software without prior written
 // authorization.
 
-// git-gosum outputs a go.sum file for one or more Git tags
+// git-gosum outputs or verifies a go.sum file for one or more Git tags or revisions, module zips, or versions of a module directory
 package main
 
 import (
+	"encoding/json"
 	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 
+	"golang.org/x/mod/module"
 	"golang.org/x/sync/errgroup"
 	"software.sslmate.com/src/sourcespotter/gosum"
+	"software.sslmate.com/src/sourcespotter/sumdb"
 )
 
 func main() {
 	log.SetPrefix("git-gosum: ")
 	log.SetFlags(0)
 
-	if len(os.Args) < 2 {
-		log.Fatal("at least one tag must be specified on the command line")
+	zipMode := flag.Bool("zip", false, "Hash existing module zip files instead of Git tags")
+	dir := flag.String("dir", "", "Hash the module in `DIR` as the given versions instead of Git tags")
+	revMode := flag.Bool("rev", false, "Hash pseudo-versions of Git revisions instead of tags")
+	subdir := flag.String("subdir", "", "With -rev, the `DIR` in the repository containing the module")
+	modulePath := flag.String("module", "", "The module `PATH`, for Git tags or revisions which have no go.mod file")
+	all := flag.Bool("all", false, "Hash every module version tag in the repository")
+	verify := flag.Bool("verify", false, "Instead of printing the go.sum lines, compare them with the checksum database in $GOSUMDB (skipping modules matched by $GONOSUMDB or $GOPRIVATE), and exit with status 1 if any differ")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: git-gosum [-verify] [-module PATH] TAG...")
+		fmt.Fprintln(os.Stderr, "       git-gosum [-verify] [-module PATH] -all")
+		fmt.Fprintln(os.Stderr, "       git-gosum -rev [-subdir DIR] [-module PATH] REVISION...")
+		fmt.Fprintln(os.Stderr, "       git-gosum -zip ZIPFILE...")
+		fmt.Fprintln(os.Stderr, "       git-gosum -dir DIR VERSION...")
+		flag.PrintDefaults()
+		os.Exit(2)
 	}
-	tags := os.Args[1:]
-	repoRoot, err := gitRoot()
-	if err != nil {
-		log.Fatal(err)
+	flag.Parse()
+	args := flag.Args()
+	gitMode := *revMode || *modulePath != ""
+	if (len(args) == 0) != *all || (*zipMode && *dir != "") || ((*zipMode || *dir != "") && gitMode) || (*subdir != "" && !*revMode) || (*all && (*zipMode || *dir != "" || *revMode)) {
+		flag.Usage()
+	}
+
+	var create func(string) (string, error)
+	switch {
+	case *zipMode:
+		create = gosum.CreateFromZip
+	case *dir != "":
+		create = func(version string) (string, error) {
+			return gosum.CreateFromDir(*dir, version)
+		}
+	case *revMode:
+		repoRoot, err := gitRoot()
+		if err != nil {
+			log.Fatal(err)
+		}
+		create = func(revision string) (string, error) {
+			return gosum.CreateFromGitRevision(repoRoot, *subdir, revision, *modulePath)
+		}
+	default:
+		repoRoot, err := gitRoot()
+		if err != nil {
+			log.Fatal(err)
+		}
+		create = func(tag string) (string, error) {
+			return gosum.CreateFromGitTagForModule(repoRoot, tag, *modulePath)
+		}
+		if *all {
+			args, err = gosum.VersionTags(repoRoot)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if len(args) == 0 {
+				log.Fatal("the repository has no module version tags")
+			}
+		}
 	}
-	goSumLines := make([]string, len(tags))
+
+	if *verify {
+		if !runVerify(args, create) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	goSumLines := make([]string, len(args))
 	group := errgroup.Group{}
 	group.SetLimit(runtime.GOMAXPROCS(0))
-	for i, tag := range tags {
+	for i, arg := range args {
 		group.Go(func() error {
 			var err error
-			goSumLines[i], err = gosum.CreateFromGitTag(repoRoot, tag)
+			goSumLines[i], err = create(arg)
 			return err
 		})
 	}
@@ -69,6 +133,93 @@ func main() {
 	}
 }
 
+// goEnv returns the values of the named Go environment variables, as the
+// go command sees them (including settings made with go env -w), or if the
+// go command is unavailable, from the environment
+func goEnv(names ...string) map[string]string {
+	values := make(map[string]string)
+	output, err := exec.Command("go", append([]string{"env", "-json"}, names...)...).Output()
+	if err == nil && json.Unmarshal(output, &values) == nil {
+		return values
+	}
+	for _, name := range names {
+		values[name] = os.Getenv(name)
+	}
+	return values
+}
+
+// runVerify compares the go.sum lines created for each arg with the
+// checksum database, and reports whether they all match.  Like the go
+// command, it never sends the paths of modules matched by GONOSUMDB (which
+// defaults to GOPRIVATE) to the checksum database.
+func runVerify(args []string, create func(string) (string, error)) bool {
+	env := goEnv("GOSUMDB", "GONOSUMDB", "GOPRIVATE")
+	key, url, err := sumdb.ParseGOSUMDB(env["GOSUMDB"])
+	if err != nil {
+		log.Fatal(err)
+	}
+	noSumDB := env["GONOSUMDB"]
+	if noSumDB == "" {
+		noSumDB = env["GOPRIVATE"]
+	}
+	client, err := sumdb.NewLookupClient(key, url)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	results := make([]string, len(args))
+	ok := make([]bool, len(args))
+	group := errgroup.Group{}
+	group.SetLimit(runtime.GOMAXPROCS(0))
+	for i, arg := range args {
+		group.Go(func() error {
+			results[i], ok[i] = verifyOne(client, noSumDB, arg, create)
+			return nil
+		})
+	}
+	group.Wait()
+
+	allOK := true
+	for i := range args {
+		fmt.Print(results[i])
+		allOK = allOK && ok[i]
+	}
+	return allOK
+}
+
+func verifyOne(client *sumdb.LookupClient, noSumDB string, arg string, create func(string) (string, error)) (string, bool) {
+	computed, err := create(arg)
+	if err != nil {
+		return fmt.Sprintf("%s: ERROR: %s\n", arg, err), false
+	}
+	fields := strings.Fields(computed)
+	modulePath, version := fields[0], fields[1]
+	if module.MatchPrefixPatterns(noSumDB, modulePath) {
+		return fmt.Sprintf("%s@%s: SKIPPED (private module matched by GONOSUMDB or GOPRIVATE)\n", modulePath, version), true
+	}
+	recorded, err := client.Lookup(modulePath, version)
+	if errors.Is(err, sumdb.ErrNotFound) {
+		return fmt.Sprintf("%s@%s: NOT FOUND in checksum database\n", modulePath, version), false
+	} else if err != nil {
+		return fmt.Sprintf("%s@%s: ERROR: %s\n", modulePath, version, err), false
+	}
+	if recorded != computed {
+		result := fmt.Sprintf("%s@%s: MISMATCH\n", modulePath, version)
+		result += indent("computed", computed)
+		result += indent("checksum database", recorded)
+		return result, false
+	}
+	return fmt.Sprintf("%s@%s: ok\n", modulePath, version), true
+}
+
+func indent(label, goSum string) string {
+	s := "\t" + label + ":\n"
+	for _, line := range strings.Split(strings.TrimSuffix(goSum, "\n"), "\n") {
+		s += "\t\t" + line + "\n"
+	}
+	return s
+}
+
 func gitRoot() (string, error) {
 	dir, err := os.Getwd()
 	if err != nil {
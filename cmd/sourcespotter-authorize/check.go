@@ -0,0 +1,234 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter/authorization"
+)
+
+// versionRecord is an element of the versions API response
+type versionRecord struct {
+	Module       string
+	Version      string
+	SourceSHA256 []byte
+	GomodSHA256  []byte
+	SumDB        string
+	Position     int64
+	ObservedAt   time.Time
+}
+
+// unauthorizedVersions returns every version of modulePath, including
+// pseudo-versions, which has not been authorized by the authorizer
+// specified by authorizerParam ("ed25519" or "identity") and authorizer
+func unauthorizedVersions(domain string, modulePath string, authorizerParam string, authorizer string) ([]versionRecord, error) {
+	var versions []versionRecord
+	cursor := ""
+	for {
+		query := url.Values{
+			"module":        {modulePath},
+			authorizerParam: {authorizer},
+			"authorized":    {"false"},
+		}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		endpoint := fmt.Sprintf("https://v1.api.%s/modules/versions?%s", domain, query.Encode())
+		resp, err := http.Get(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			msg, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("versions request failed: %s", strings.TrimSpace(resp.Status+": "+string(msg)))
+		}
+		var page struct {
+			Versions   []versionRecord
+			NextCursor string
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding versions response: %w", err)
+		}
+		versions = append(versions, page.Versions...)
+		if page.NextCursor == "" {
+			return versions, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func runCheck(args []string, format string) (bool, error) {
+	authorizerParam, authorizer := "ed25519", ""
+	if len(args) == 1 && strings.Contains(args[0], ":") {
+		if _, err := authorization.ParseIdentity(args[0]); err != nil {
+			return false, fmt.Errorf("invalid identity %q: %w", args[0], err)
+		}
+		authorizerParam, authorizer = "identity", args[0]
+	} else if len(args) == 1 {
+		key, err := base64.StdEncoding.DecodeString(args[0])
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return false, fmt.Errorf("invalid public key %q", args[0])
+		}
+		authorizer = args[0]
+	} else {
+		signer, err := loadSigner()
+		if err != nil {
+			return false, err
+		}
+		authorizer = base64.StdEncoding.EncodeToString(signer.Public().(ed25519.PublicKey))
+	}
+
+	goModPath, err := goModFromGoEnv()
+	if err != nil {
+		return false, err
+	}
+	modulePath, err := modulePathFromFile(goModPath)
+	if err != nil {
+		return false, err
+	}
+
+	versions, err := unauthorizedVersions(sourcespotterDomain(), modulePath, authorizerParam, authorizer)
+	if err != nil {
+		return false, err
+	}
+
+	switch format {
+	case "text":
+		writeCheckText(os.Stdout, versions)
+	case "json":
+		writeCheckJSON(os.Stdout, versions)
+	case "sarif":
+		writeCheckSARIF(os.Stdout, versions, sarifLocation(goModPath))
+	}
+	return len(versions) == 0, nil
+}
+
+func describeVersion(v *versionRecord) string {
+	return fmt.Sprintf("%s@%s was published to %s at %s, but has not been authorized (h1:%s)", v.Module, v.Version, v.SumDB, v.ObservedAt.UTC().Format(time.RFC3339), base64.StdEncoding.EncodeToString(v.SourceSHA256))
+}
+
+func writeCheckText(w io.Writer, versions []versionRecord) {
+	for i := range versions {
+		fmt.Fprintln(w, describeVersion(&versions[i]))
+	}
+}
+
+func writeCheckJSON(w io.Writer, versions []versionRecord) {
+	if versions == nil {
+		versions = []versionRecord{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(struct{ Unauthorized []versionRecord }{versions})
+}
+
+// sarifLocation returns the path of go.mod relative to the root of the
+// repository, which is where code scanning tools expect it
+func sarifLocation(goModPath string) string {
+	if repoRoot, err := gitRoot(); err == nil {
+		if rel, err := filepath.Rel(repoRoot, goModPath); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return "go.mod"
+}
+
+func writeCheckSARIF(w io.Writer, versions []versionRecord, goModURI string) {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type location struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+		} `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+	type rule struct {
+		ID               string  `json:"id"`
+		ShortDescription message `json:"shortDescription"`
+	}
+	type driver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+		Rules          []rule `json:"rules"`
+	}
+	type run struct {
+		Tool struct {
+			Driver driver `json:"driver"`
+		} `json:"tool"`
+		Results []result `json:"results"`
+	}
+	type sarifLog struct {
+		Version string `json:"version"`
+		Schema  string `json:"$schema"`
+		Runs    []run  `json:"runs"`
+	}
+
+	var loc location
+	loc.PhysicalLocation.ArtifactLocation.URI = goModURI
+	r := run{Results: []result{}}
+	r.Tool.Driver = driver{
+		Name:           "sourcespotter-authorize",
+		InformationURI: "https://" + sourcespotterDomain() + "/modules/",
+		Rules:          []rule{{ID: "unauthorized-version", ShortDescription: message{"Module version published without authorization"}}},
+	}
+	for i := range versions {
+		r.Results = append(r.Results, result{
+			RuleID:    "unauthorized-version",
+			Level:     "error",
+			Message:   message{describeVersion(&versions[i])},
+			Locations: []location{loc},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []run{r},
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"software.sslmate.com/src/sourcespotter/gosum"
+)
+
+const hookMarker = "# Installed by sourcespotter-authorize -install-hook"
+
+// runInstallHook installs a pre-push hook.  Other hooks, such as
+// reference-transaction, are deliberately unsupported: they also run when
+// tags are fetched from a remote, and would authorize tags which someone
+// else created.
+func runInstallHook() error {
+	const hookName = "pre-push"
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	output, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return fmt.Errorf("unable to locate Git hooks directory: %w", err)
+	}
+	hooksDir := strings.TrimSpace(string(output))
+	hookPath := filepath.Join(hooksDir, hookName)
+	command := shellQuote(executable) + " -run-hook " + hookName + ` "$@" || true`
+
+	if existing, err := os.ReadFile(hookPath); err == nil && !strings.Contains(string(existing), hookMarker) {
+		return fmt.Errorf("%s already exists: add the following line to it instead:\n\t%s", hookPath, command)
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(hooksDir, 0777); err != nil {
+		return err
+	}
+	script := "#!/bin/sh\n" + hookMarker + "\n" + command + "\nexit 0\n"
+	if err := os.WriteFile(hookPath, []byte(script), 0777); err != nil {
+		return err
+	}
+	if err := os.Chmod(hookPath, 0777); err != nil {
+		return err
+	}
+	fmt.Printf("Installed %s\n", hookPath)
+	return nil
+}
+
+// runHook is run by the hook installed by -install-hook.  It authorizes
+// the version tags which are being pushed.  Failures are reported, but never
+// block the push.
+func runHook(hookName string) {
+	tags, err := hookTags(hookName, os.Stdin)
+	if err != nil {
+		log.Printf("warning: %s hook: %s", hookName, err)
+		return
+	}
+	if len(tags) == 0 {
+		return
+	}
+	log.Printf("authorizing %s", strings.Join(tags, " "))
+	if err := runAuthorizeTags(tags); err != nil {
+		log.Printf("warning: failed to authorize %s: %s", strings.Join(tags, " "), err)
+		log.Printf("the push will continue; to retry, run: sourcespotter-authorize %s", strings.Join(tags, " "))
+	}
+}
+
+// hookTags returns the new version tags listed in a hook's standard input
+func hookTags(hookName string, stdin io.Reader) ([]string, error) {
+	var tags []string
+	addTag := func(ref string) {
+		if tag, ok := strings.CutPrefix(ref, "refs/tags/"); ok && gosum.IsVersionTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	scanner := bufio.NewScanner(stdin)
+	switch hookName {
+	case "pre-push":
+		// <local ref> <local oid> <remote ref> <remote oid>
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) != 4 {
+				continue
+			}
+			localRef, localOID, remoteRef, remoteOID := fields[0], fields[1], fields[2], fields[3]
+			if localRef == remoteRef && !isZeroOID(localOID) && isZeroOID(remoteOID) {
+				addTag(localRef)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported hook %q", hookName)
+	}
+	return tags, scanner.Err()
+}
+
+func isZeroOID(oid string) bool {
+	return strings.Trim(oid, "0") == ""
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
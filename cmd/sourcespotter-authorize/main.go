@@ -28,6 +28,7 @@ package main
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/base64"
@@ -44,18 +45,32 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"golang.org/x/mod/modfile"
 	"golang.org/x/sync/errgroup"
+	"software.sslmate.com/src/sourcespotter/authorization"
 	"software.sslmate.com/src/sourcespotter/gosum"
+	"software.sslmate.com/src/sourcespotter/internal/keyfile"
+	"software.sslmate.com/src/sourcespotter/sshagent"
 )
 
 const (
 	defaultDomain = "sourcespotter.com"
 )
 
+// sshKey is the -ssh-key flag
+var sshKey string
+
 func usage() {
-	fmt.Fprintln(os.Stderr, "usage: sourcespotter-authorize [-keygen|-pubkey|-feed] [TAG...]")
+	fmt.Fprintln(os.Stderr, "usage: sourcespotter-authorize [-keygen [-encrypt]|-encrypt|-pubkey|-feed|-rotate] [TAG...]")
+	fmt.Fprintln(os.Stderr, "       sourcespotter-authorize [-oidc] -zip ZIPFILE...")
+	fmt.Fprintln(os.Stderr, "       sourcespotter-authorize [-oidc] -dir DIR VERSION...")
+	fmt.Fprintln(os.Stderr, "       sourcespotter-authorize [-oidc] -gosum FILE")
+	fmt.Fprintln(os.Stderr, "       sourcespotter-authorize -oidc TAG...")
+	fmt.Fprintln(os.Stderr, "       sourcespotter-authorize -revoke [-since TIME] [PUBKEY]")
+	fmt.Fprintln(os.Stderr, "       sourcespotter-authorize -check [-format text|json|sarif] [PUBKEY|IDENTITY]")
+	fmt.Fprintln(os.Stderr, "       sourcespotter-authorize -install-hook")
 	flag.PrintDefaults()
 	os.Exit(2)
 }
@@ -65,18 +80,34 @@ func main() {
 	log.SetFlags(0)
 
 	keygen := flag.Bool("keygen", false, "Generate a new Ed25519 private key")
+	encrypt := flag.Bool("encrypt", false, "With -keygen, encrypt the new private key with a passphrase; on its own, encrypt the existing private key")
 	pubkey := flag.Bool("pubkey", false, "Print the Ed25519 public key in base64")
 	feed := flag.Bool("feed", false, "Print the modules feed URL")
+	rotate := flag.Bool("rotate", false, "Replace the private key with a new one, endorsed by the old key")
+	revoke := flag.Bool("revoke", false, "Revoke the public key (or PUBKEY, a key that was rotated to the current key)")
+	since := flag.String("since", "", "With -revoke, ignore versions authorized at or after this RFC 3339 `TIME` (default now)")
+	check := flag.Bool("check", false, "List versions of the current module which have not been authorized, and exit with status 1 if there are any")
+	format := flag.String("format", "", "With -check, the output `FORMAT`: text, json, or sarif (default text)")
+	zipMode := flag.Bool("zip", false, "Authorize the module versions in existing module zip files, such as those built by a release pipeline")
+	dir := flag.String("dir", "", "Authorize the module in `DIR` (which should be a clean checkout) as the given versions")
+	goSumFile := flag.String("gosum", "", "Sign and submit the go.sum lines in `FILE` (- for standard input)")
+	installHook := flag.Bool("install-hook", false, "Install a Git pre-push hook which authorizes version tags automatically when they are pushed")
+	runHookName := flag.String("run-hook", "", "Run as the Git `HOOK` installed by -install-hook")
+	flag.BoolVar(&useOIDC, "oidc", false, "Authenticate authorizations with the GitHub Actions workflow's OIDC identity token instead of a key")
+	flag.StringVar(&sshKey, "ssh-key", os.Getenv("SOURCESPOTTER_AUTHORIZE_SSH_KEY"), "Sign with the Ed25519 `KEY` held by the SSH agent at $SSH_AUTH_SOCK instead of the private key file (either a .pub file or the public key itself)")
 	flag.Usage = usage
 	flag.Parse()
 
 	modeCount := 0
-	for _, enabled := range []bool{*keygen, *pubkey, *feed} {
+	for _, enabled := range []bool{*keygen, *encrypt && !*keygen, *pubkey, *feed, *rotate, *revoke, *check, *zipMode, *dir != "", *goSumFile != "", *installHook, *runHookName != ""} {
 		if enabled {
 			modeCount++
 		}
 	}
-	if modeCount > 1 {
+	if modeCount > 1 || (*since != "" && !*revoke) || (*format != "" && !*check) {
+		usage()
+	}
+	if useOIDC && (sshKey != "" || *keygen || *encrypt || *pubkey || *feed || *rotate || *revoke || *check || *installHook || *runHookName != "") {
 		usage()
 	}
 
@@ -86,7 +117,14 @@ func main() {
 		if len(args) != 0 {
 			usage()
 		}
-		if err := runKeygen(); err != nil {
+		if err := runKeygen(*encrypt); err != nil {
+			log.Fatal(err)
+		}
+	case *encrypt:
+		if len(args) != 0 {
+			usage()
+		}
+		if err := runEncrypt(); err != nil {
 			log.Fatal(err)
 		}
 	case *pubkey:
@@ -103,17 +141,82 @@ func main() {
 		if err := runFeed(); err != nil {
 			log.Fatal(err)
 		}
+	case *rotate:
+		if len(args) != 0 {
+			usage()
+		}
+		if err := runRotate(); err != nil {
+			log.Fatal(err)
+		}
+	case *revoke:
+		if len(args) > 1 {
+			usage()
+		}
+		if err := runRevoke(args, *since); err != nil {
+			log.Fatal(err)
+		}
+	case *check:
+		if len(args) > 1 {
+			usage()
+		}
+		switch *format {
+		case "":
+			*format = "text"
+		case "text", "json", "sarif":
+		default:
+			usage()
+		}
+		ok, err := runCheck(args, *format)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	case *installHook:
+		if len(args) > 0 {
+			usage()
+		}
+		if err := runInstallHook(); err != nil {
+			log.Fatal(err)
+		}
+	case *runHookName != "":
+		runHook(*runHookName)
+	case *zipMode:
+		if len(args) == 0 {
+			usage()
+		}
+		if err := runAuthorize(args, gosum.CreateFromZip); err != nil {
+			log.Fatal(err)
+		}
+	case *dir != "":
+		if len(args) == 0 {
+			usage()
+		}
+		createFromDir := func(version string) (string, error) {
+			return gosum.CreateFromDir(*dir, version)
+		}
+		if err := runAuthorize(args, createFromDir); err != nil {
+			log.Fatal(err)
+		}
+	case *goSumFile != "":
+		if len(args) != 0 {
+			usage()
+		}
+		if err := runAuthorizeGoSum(*goSumFile); err != nil {
+			log.Fatal(err)
+		}
 	default:
 		if len(args) == 0 {
 			usage()
 		}
-		if err := runAuthorize(args); err != nil {
+		if err := runAuthorizeTags(args); err != nil {
 			log.Fatal(err)
 		}
 	}
 }
 
-func runKeygen() error {
+func runKeygen(encrypt bool) error {
 	_, priv, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
 		return err
@@ -123,17 +226,63 @@ func runKeygen() error {
 	if err != nil {
 		return err
 	}
+	if _, err := os.Stat(keyPath); err == nil {
+		return fmt.Errorf("private key file %q already exists", keyPath)
+	}
+	if encrypt {
+		if _, err := getPassphrase(true); err != nil {
+			return err
+		}
+	}
 	if err := os.MkdirAll(filepath.Dir(keyPath), 0777); err != nil {
 		return err
 	}
+	return writePrivateKey(keyPath, priv)
+}
+
+// runEncrypt replaces an unencrypted private key file with an encrypted one
+func runEncrypt() error {
+	keyPath, err := keyPath()
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	if keyfile.IsEncrypted(content) {
+		return fmt.Errorf("private key file %q is already encrypted", keyPath)
+	}
+	priv, err := keyfile.Parse(content, nil)
+	if err != nil {
+		return fmt.Errorf("invalid private key file %q: %w", keyPath, err)
+	}
+	if _, err := getPassphrase(true); err != nil {
+		return err
+	}
+	newKeyPath := keyPath + ".new"
+	if err := writePrivateKey(newKeyPath, priv); err != nil {
+		return err
+	}
+	return os.Rename(newKeyPath, keyPath)
+}
 
-	content := fmt.Sprintf("ed25519\n%s\n", base64.StdEncoding.EncodeToString(priv))
+// writePrivateKey writes priv to a new file, encrypted if a passphrase has been read
+func writePrivateKey(keyPath string, priv ed25519.PrivateKey) error {
+	content := keyfile.Marshal(priv)
+	if passphrase != nil {
+		var err error
+		content, err = keyfile.MarshalEncrypted(priv, passphrase)
+		if err != nil {
+			return err
+		}
+	}
 	file, err := os.OpenFile(keyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	if _, err := file.WriteString(content); err != nil {
+	if _, err := file.Write(content); err != nil {
 		return fmt.Errorf("error writing private key file %q: %w", keyPath, err)
 	}
 	if err := file.Close(); err != nil {
@@ -143,21 +292,21 @@ func runKeygen() error {
 }
 
 func runPubkey() error {
-	priv, err := readPrivateKey()
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
-	pub := priv.Public().(ed25519.PublicKey)
+	pub := signer.Public().(ed25519.PublicKey)
 	fmt.Println(base64.StdEncoding.EncodeToString(pub))
 	return nil
 }
 
 func runFeed() error {
-	priv, err := readPrivateKey()
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
-	pub := priv.Public().(ed25519.PublicKey)
+	pub := signer.Public().(ed25519.PublicKey)
 	pub64 := base64.StdEncoding.EncodeToString(pub)
 
 	modulePath, err := modulePathFromGoEnv()
@@ -176,52 +325,166 @@ func runFeed() error {
 	return nil
 }
 
-func runAuthorize(tags []string) error {
+func runAuthorizeTags(tags []string) error {
 	repoRoot, err := gitRoot()
 	if err != nil {
 		return err
 	}
-	priv, err := readPrivateKey()
+	createFromGitTag := func(tag string) (string, error) {
+		return gosum.CreateFromGitTag(repoRoot, tag)
+	}
+	return runAuthorize(tags, createFromGitTag)
+}
+
+// runAuthorize authorizes the go.sum lines created by calling create on each arg
+func runAuthorize(args []string, create func(string) (string, error)) error {
+	signer, err := loadAuthorizationSigner()
 	if err != nil {
 		return err
 	}
-	pub := priv.Public().(ed25519.PublicKey)
 
-	domain := sourcespotterDomain()
-	endpoint := fmt.Sprintf("https://v1.api.%s/modules/authorized", domain)
-
-	goSumLines := make([]string, len(tags))
+	goSumLines := make([]string, len(args))
 	group := errgroup.Group{}
 	group.SetLimit(runtime.GOMAXPROCS(0))
-	for i, tag := range tags {
+	for i, arg := range args {
 		group.Go(func() error {
 			var err error
-			goSumLines[i], err = gosum.CreateFromGitTag(repoRoot, tag)
+			goSumLines[i], err = create(arg)
 			return err
 		})
 	}
 	if err := group.Wait(); err != nil {
 		return err
 	}
+	return submitGoSum(signer, strings.Join(goSumLines, ""))
+}
+
+func runAuthorizeGoSum(path string) error {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	goSum := string(content)
+	if err := gosum.Check(goSum); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	signer, err := loadAuthorizationSigner()
+	if err != nil {
+		return err
+	}
+	return submitGoSum(signer, goSum)
+}
 
-	goSum := strings.Join(goSumLines, "")
-	sig := ed25519.Sign(priv, []byte(goSum))
+// submitGoSum submits goSum, signed by signer, or if signer is nil,
+// authenticated by an OIDC identity token
+func submitGoSum(signer crypto.Signer, goSum string) error {
+	domain := sourcespotterDomain()
+	endpoint := fmt.Sprintf("https://v1.api.%s/modules/authorized", domain)
+	payload, err := authorization.NewPayload(domain, goSum)
+	if err != nil {
+		return err
+	}
+	var submission *authorization.Submission
+	if signer == nil {
+		submission, err = newKeylessSubmission(domain, payload)
+	} else {
+		submission, err = authorization.SignWith(signer, payload)
+	}
+	if err != nil {
+		return err
+	}
+	return postAuthorized(endpoint, submission)
+}
 
-	payload := struct {
-		Ed25519   []byte
-		GoSum     string
-		Signature []byte
-	}{
-		Ed25519:   pub,
-		GoSum:     goSum,
-		Signature: sig,
+func runRotate() error {
+	if sshKey != "" {
+		return errors.New("-rotate only supports private key files, not SSH agent keys")
+	}
+	oldPriv, err := readPrivateKey()
+	if err != nil {
+		return err
+	}
+	newPub, newPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	keyPath, err := keyPath()
+	if err != nil {
+		return err
+	}
+
+	// Save the new key before submitting the rotation, so it can't be lost
+	newKeyPath := keyPath + ".new"
+	if err := writePrivateKey(newKeyPath, newPriv); err != nil {
+		return err
+	}
+
+	domain := sourcespotterDomain()
+	rotation := &authorization.Rotation{
+		Domain:    domain,
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+		Old:       oldPriv.Public().(ed25519.PublicKey),
+		New:       newPub,
+	}
+	endpoint := fmt.Sprintf("https://v1.api.%s/modules/keys/statements", domain)
+	if err := postAuthorized(endpoint, authorization.SignRotation(oldPriv, newPriv, rotation)); err != nil {
+		return fmt.Errorf("%w (the new key has been saved to %q)", err, newKeyPath)
+	}
+
+	if err := os.Rename(keyPath, keyPath+".old"); err != nil {
+		return err
 	}
-	if err := postAuthorized(endpoint, payload); err != nil {
+	if err := os.Rename(newKeyPath, keyPath); err != nil {
 		return err
 	}
+	fmt.Println(base64.StdEncoding.EncodeToString(newPub))
 	return nil
 }
 
+func runRevoke(args []string, sinceFlag string) error {
+	signer, err := loadSigner()
+	if err != nil {
+		return err
+	}
+	revokedKey := signer.Public().(ed25519.PublicKey)
+	if len(args) == 1 {
+		key, err := base64.StdEncoding.DecodeString(args[0])
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid public key %q", args[0])
+		}
+		revokedKey = key
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	since := now
+	if sinceFlag != "" {
+		since, err = time.Parse(time.RFC3339, sinceFlag)
+		if err != nil {
+			return fmt.Errorf("invalid -since time: %w", err)
+		}
+	}
+
+	domain := sourcespotterDomain()
+	revocation := &authorization.Revocation{
+		Domain:    domain,
+		Timestamp: now,
+		Key:       revokedKey,
+		Since:     since,
+	}
+	endpoint := fmt.Sprintf("https://v1.api.%s/modules/keys/statements", domain)
+	statement, err := authorization.SignStatementWith(signer, revocation)
+	if err != nil {
+		return err
+	}
+	return postAuthorized(endpoint, statement)
+}
+
 func keyPath() (string, error) {
 	if envPath := os.Getenv("SOURCESPOTTER_AUTHORIZE_KEY"); envPath != "" {
 		return envPath, nil
@@ -240,6 +503,40 @@ func sourcespotterDomain() string {
 	return defaultDomain
 }
 
+// loadAuthorizationSigner returns the signer for authorizations, or nil
+// if they are authenticated with an OIDC identity token instead (-oidc)
+func loadAuthorizationSigner() (crypto.Signer, error) {
+	if useOIDC {
+		return nil, nil
+	}
+	return loadSigner()
+}
+
+// loadSigner returns the SSH agent key selected by -ssh-key, or else the
+// private key from the key file
+func loadSigner() (crypto.Signer, error) {
+	if sshKey == "" {
+		return readPrivateKey()
+	}
+	keyText := sshKey
+	if !strings.HasPrefix(keyText, "ssh-") {
+		if content, err := os.ReadFile(keyText); err == nil {
+			keyText = string(content)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	pubkey, err := sshagent.ParsePublicKey(keyText)
+	if err != nil {
+		return nil, fmt.Errorf("-ssh-key: %w", err)
+	}
+	agent, err := sshagent.Dial()
+	if err != nil {
+		return nil, err
+	}
+	return sshagent.NewSigner(agent, pubkey)
+}
+
 func readPrivateKey() (ed25519.PrivateKey, error) {
 	keyPath, err := keyPath()
 	if err != nil {
@@ -248,28 +545,35 @@ func readPrivateKey() (ed25519.PrivateKey, error) {
 	content, err := os.ReadFile(keyPath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("private key file %q not found: run 'sourcespotter-authorize -keygen' to generate it or set $SOURCESPOTTER_AUTHORIZE_KEY to a different path", keyPath)
+			return nil, fmt.Errorf("private key file %q not found: run 'sourcespotter-authorize -keygen' to generate it or set $SOURCESPOTTER_AUTHORIZE_KEY to a different path (or use -ssh-key to sign with an SSH agent)", keyPath)
 		}
 		return nil, err
 	}
-	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
-	if len(lines) != 2 {
-		return nil, fmt.Errorf("invalid private key file %q: expected two lines", keyPath)
-	}
-	if strings.TrimSpace(lines[0]) != "ed25519" {
-		return nil, fmt.Errorf("invalid private key file %q: unsupported algorithm", keyPath)
-	}
-	keyBytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	var passphraseErr error
+	priv, err := keyfile.Parse(content, func() ([]byte, error) {
+		pass, err := getPassphrase(false)
+		passphraseErr = err
+		return pass, err
+	})
+	if passphraseErr != nil {
+		return nil, passphraseErr
+	} else if errors.Is(err, keyfile.ErrWrongPassphrase) {
+		return nil, fmt.Errorf("wrong passphrase for private key file %q", keyPath)
+	} else if err != nil {
+		return nil, fmt.Errorf("invalid private key file %q: %w", keyPath, err)
+	}
+	return priv, nil
+}
+
+func modulePathFromGoEnv() (string, error) {
+	path, err := goModFromGoEnv()
 	if err != nil {
-		return nil, err
-	}
-	if len(keyBytes) != ed25519.PrivateKeySize {
-		return nil, fmt.Errorf("invalid private key file %q: invalid length", keyPath)
+		return "", err
 	}
-	return ed25519.PrivateKey(keyBytes), nil
+	return modulePathFromFile(path)
 }
 
-func modulePathFromGoEnv() (string, error) {
+func goModFromGoEnv() (string, error) {
 	cmd := exec.Command("go", "env", "GOMOD")
 	output, err := cmd.Output()
 	if err != nil {
@@ -279,7 +583,7 @@ func modulePathFromGoEnv() (string, error) {
 	if path == "" || path == os.DevNull {
 		return "", errors.New("no go.mod found: run this command from within a Go module")
 	}
-	return modulePathFromFile(path)
+	return path, nil
 }
 
 func modulePathFromFile(path string) (string, error) {
@@ -0,0 +1,100 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"software.sslmate.com/src/sourcespotter/authorization"
+)
+
+// useOIDC is the -oidc flag
+var useOIDC bool
+
+// requestIdentityToken requests an OIDC identity token for audience from
+// GitHub Actions, which provides it to workflows with the id-token: write
+// permission
+func requestIdentityToken(audience string) (string, error) {
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", errors.New("-oidc requires a GitHub Actions workflow with the id-token: write permission")
+	}
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid $ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("audience", audience)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("identity token request failed: %s", strings.TrimSpace(resp.Status+": "+string(msg)))
+	}
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("error decoding identity token response: %w", err)
+	}
+	if body.Value == "" {
+		return "", errors.New("identity token response is empty")
+	}
+	return body.Value, nil
+}
+
+// newKeylessSubmission returns a keyless submission of payload,
+// authenticated by an identity token bound to it
+func newKeylessSubmission(domain string, payload *authorization.Payload) (*authorization.Submission, error) {
+	data := string(payload.Marshal())
+	token, err := requestIdentityToken(authorization.IdentityAudience(domain, data))
+	if err != nil {
+		return nil, err
+	}
+	return &authorization.Submission{
+		Version:       2,
+		Payload:       data,
+		IdentityToken: token,
+	}, nil
+}
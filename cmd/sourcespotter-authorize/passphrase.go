@@ -0,0 +1,115 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// passphrase is the private key's passphrase, once it has been read
+var passphrase []byte
+
+// getPassphrase returns the passphrase from $SOURCESPOTTER_AUTHORIZE_PASSPHRASE,
+// the file descriptor in $SOURCESPOTTER_AUTHORIZE_PASSPHRASE_FD (for CI), or
+// else the terminal.  If confirm is true and the passphrase is read from the
+// terminal, it must be entered twice.
+func getPassphrase(confirm bool) ([]byte, error) {
+	if passphrase != nil {
+		return passphrase, nil
+	}
+	pass, err := readPassphrase(confirm)
+	if err != nil {
+		return nil, err
+	}
+	if len(pass) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+	passphrase = pass
+	return passphrase, nil
+}
+
+func readPassphrase(confirm bool) ([]byte, error) {
+	if pass, ok := os.LookupEnv("SOURCESPOTTER_AUTHORIZE_PASSPHRASE"); ok {
+		return []byte(pass), nil
+	}
+	if fdString := os.Getenv("SOURCESPOTTER_AUTHORIZE_PASSPHRASE_FD"); fdString != "" {
+		fd, err := strconv.ParseUint(fdString, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $SOURCESPOTTER_AUTHORIZE_PASSPHRASE_FD: %w", err)
+		}
+		file := os.NewFile(uintptr(fd), "passphrase")
+		if file == nil {
+			return nil, fmt.Errorf("invalid $SOURCESPOTTER_AUTHORIZE_PASSPHRASE_FD %d", fd)
+		}
+		defer file.Close()
+		line, err := bufio.NewReader(file).ReadBytes('\n')
+		if err != nil && len(line) == 0 {
+			return nil, fmt.Errorf("error reading passphrase from file descriptor %d: %w", fd, err)
+		}
+		return bytes.TrimRight(line, "\r\n"), nil
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, errors.New("the private key is encrypted, but there is no terminal to prompt for the passphrase: set $SOURCESPOTTER_AUTHORIZE_PASSPHRASE or $SOURCESPOTTER_AUTHORIZE_PASSPHRASE_FD")
+		}
+		tty = os.Stdin
+	} else {
+		defer tty.Close()
+	}
+	pass, err := promptPassphrase(tty, "Passphrase for sourcespotter-authorize key: ")
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		again, err := promptPassphrase(tty, "Enter the same passphrase again: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(pass, again) {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	return pass, nil
+}
+
+func promptPassphrase(tty *os.File, prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	pass, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("error reading passphrase: %w", err)
+	}
+	return pass, nil
+}
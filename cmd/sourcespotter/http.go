@@ -31,17 +31,25 @@ import (
 	"time"
 
 	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/authlog"
 	"software.sslmate.com/src/sourcespotter/internal/cooldown"
 	"software.sslmate.com/src/sourcespotter/internal/dashboard"
 	"software.sslmate.com/src/sourcespotter/internal/deps"
+	"software.sslmate.com/src/sourcespotter/internal/gomod"
 	"software.sslmate.com/src/sourcespotter/internal/modcheck"
+	"software.sslmate.com/src/sourcespotter/internal/modrepro"
 	"software.sslmate.com/src/sourcespotter/internal/modules"
+	"software.sslmate.com/src/sourcespotter/internal/origin"
+	"software.sslmate.com/src/sourcespotter/internal/ownership"
+	"software.sslmate.com/src/sourcespotter/internal/pathcheck"
+	"software.sslmate.com/src/sourcespotter/internal/proxycheck"
 	"software.sslmate.com/src/sourcespotter/internal/sths"
 	"software.sslmate.com/src/sourcespotter/internal/sumdb"
 	"software.sslmate.com/src/sourcespotter/internal/telemetry"
 	"software.sslmate.com/src/sourcespotter/internal/toolchain"
 	"software.sslmate.com/src/sourcespotter/internal/toolchainvuln"
 	"software.sslmate.com/src/sourcespotter/internal/vulns"
+	"software.sslmate.com/src/sourcespotter/internal/webhooks"
 	"src.agwa.name/go-util/logfilter"
 )
 
@@ -60,6 +68,11 @@ func newHTTPServer() *http.Server {
 	mux.HandleFunc("GET "+domain+"/telemetry/{$}", telemetry.ServeDashboard)
 	mux.HandleFunc("GET "+domain+"/modcheck/{$}", modcheck.ServeDashboard)
 	mux.HandleFunc("GET "+domain+"/toolchainvuln/{$}", toolchainvuln.ServeDashboard)
+	mux.HandleFunc("GET "+domain+"/dependents/{$}", gomod.ServeDashboard)
+	mux.HandleFunc("GET "+domain+"/proxycheck/{$}", proxycheck.ServeDashboard)
+	mux.HandleFunc("GET "+domain+"/origin/{$}", origin.ServeDashboard)
+	mux.HandleFunc("GET "+domain+"/modrepro/{$}", modrepro.ServeDashboard)
+	mux.HandleFunc("GET "+domain+"/pathcheck/{$}", pathcheck.ServeDashboard)
 	// private API
 	mux.HandleFunc("POST private.api."+domain+"/toolchainvuln/announcement", toolchainvuln.ReceiveAnnouncement)
 	// badges API
@@ -72,7 +85,15 @@ func newHTTPServer() *http.Server {
 	mux.HandleFunc("GET feeds.api."+domain+"/telemetry/counters.atom", telemetry.ServeCountersAtom)
 	mux.HandleFunc("GET feeds.api."+domain+"/telemetry/counters.csv", telemetry.ServeCountersCSV)
 	mux.HandleFunc("GET feeds.api."+domain+"/modules/versions.atom", modules.ServeVersionsAtom)
+	mux.HandleFunc("GET feeds.api."+domain+"/modules/unrecorded.atom", modules.ServeUnrecordedAtom)
+	mux.HandleFunc("GET feeds.api."+domain+"/modules/unusual.atom", modules.ServeUnusualAtom)
+	mux.HandleFunc("GET feeds.api."+domain+"/modules/keys.atom", ownership.ServeKeysAtom)
 	mux.HandleFunc("GET feeds.api."+domain+"/toolchainvuln/unpublished.atom", toolchainvuln.ServeUnpublishedAtom)
+	mux.HandleFunc("GET feeds.api."+domain+"/proxycheck/mismatches.atom", proxycheck.ServeMismatchesAtom)
+	mux.HandleFunc("GET feeds.api."+domain+"/proxycheck/unrecorded.atom", proxycheck.ServeUnrecordedAtom)
+	mux.HandleFunc("GET feeds.api."+domain+"/origin/alerts.atom", origin.ServeAlertsAtom)
+	mux.HandleFunc("GET feeds.api."+domain+"/modrepro/failures.atom", modrepro.ServeFailuresAtom)
+	mux.HandleFunc("GET feeds.api."+domain+"/pathcheck/alerts.atom", pathcheck.ServeAlertsAtom)
 	// gossip API
 	mux.HandleFunc("GET gossip.api."+domain+"/{address}", sths.ServeGossip)
 	mux.HandleFunc("POST gossip.api."+domain+"/{address}", sths.ReceiveGossip)
@@ -81,6 +102,22 @@ func newHTTPServer() *http.Server {
 	// v1 public API
 	mux.HandleFunc("POST v1.api."+domain+"/modules/authorized", modules.ReceiveAuthorized)
 	mux.HandleFunc("GET v1.api."+domain+"/modules/authorized", modules.ServeAuthorized)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/authorized/submissions", modules.ServeSubmissions)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/authorized/log/checkpoint", authlog.ServeCheckpoint)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/authorized/log/key", authlog.ServeKey)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/authorized/log/tile/{path...}", authlog.ServeTile)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/versions", modules.ServeVersions)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/dependents", gomod.ServeDependents)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/risk", modules.ServeRisk)
+	mux.HandleFunc("POST v1.api."+domain+"/modules/keys", ownership.ReceiveProof)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/keys", ownership.ServeKeys)
+	mux.HandleFunc("POST v1.api."+domain+"/modules/keys/statements", modules.ReceiveKeyStatement)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/keys/statements", modules.ServeKeyStatements)
+	mux.HandleFunc("POST v1.api."+domain+"/modules/policies", modules.ReceivePolicy)
+	mux.HandleFunc("GET v1.api."+domain+"/modules/policies", modules.ServePolicies)
+	mux.HandleFunc("POST v1.api."+domain+"/webhooks", webhooks.ReceiveSubscription)
+	mux.HandleFunc("DELETE v1.api."+domain+"/webhooks/{id}", webhooks.DeleteSubscription)
+	mux.HandleFunc("GET v1.api."+domain+"/webhooks/{id}/deliveries", webhooks.ServeDeliveries)
 
 	return &http.Server{
 		ReadTimeout:  5 * time.Second,
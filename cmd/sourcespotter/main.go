@@ -39,7 +39,13 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/authlog"
 	"software.sslmate.com/src/sourcespotter/internal/dashboard"
+	"software.sslmate.com/src/sourcespotter/internal/modrepro"
+	"software.sslmate.com/src/sourcespotter/internal/modules"
+	"software.sslmate.com/src/sourcespotter/internal/oidc"
+	"software.sslmate.com/src/sourcespotter/internal/pathcheck"
+	"software.sslmate.com/src/sourcespotter/internal/proxycheck"
 	"software.sslmate.com/src/sourcespotter/internal/toolchain"
 	"software.sslmate.com/src/sourcespotter/internal/toolchainvuln"
 	"src.agwa.name/go-listener"
@@ -48,18 +54,34 @@ import (
 
 func main() {
 	var flags struct {
-		config    string
-		files     string
-		sumdb     bool
-		toolchain bool
-		telemetry bool
-		listen    []string
+		config     string
+		files      string
+		sumdb      bool
+		toolchain  bool
+		telemetry  bool
+		gomod      bool
+		proxycheck bool
+		origin     bool
+		modrepro   bool
+		pathcheck  bool
+		activity   bool
+		webhooks   bool
+		ownership  bool
+		listen     []string
 	}
 	flag.StringVar(&flags.config, "config", "", "Path to configuration file")
 	flag.StringVar(&flags.files, "files", "", "Path to templates and assets to override embedded copies")
 	flag.BoolVar(&flags.sumdb, "sumdb", false, "Enable sumdb monitoring")
 	flag.BoolVar(&flags.toolchain, "toolchain", false, "Enable toolchain auditing")
 	flag.BoolVar(&flags.telemetry, "telemetry", false, "Enable telemetry config monitoring")
+	flag.BoolVar(&flags.gomod, "gomod", false, "Enable go.mod indexing")
+	flag.BoolVar(&flags.proxycheck, "proxycheck", false, "Enable module proxy integrity checking")
+	flag.BoolVar(&flags.origin, "origin", false, "Enable module origin tracking")
+	flag.BoolVar(&flags.modrepro, "modrepro", false, "Enable module reproducibility checking")
+	flag.BoolVar(&flags.pathcheck, "pathcheck", false, "Enable module path confusion detection")
+	flag.BoolVar(&flags.activity, "activity", false, "Enable unusual publishing activity detection")
+	flag.BoolVar(&flags.webhooks, "webhooks", false, "Enable webhook notifications of unauthorized versions")
+	flag.BoolVar(&flags.ownership, "ownership", false, "Enable periodic re-verification of maintainer key ownership")
 	flag.Func("listen", "Run HTTP server on `LISTENER`, in go-listener syntax (repeatable)", func(arg string) error {
 		flags.listen = append(flags.listen, arg)
 		return nil
@@ -89,6 +111,22 @@ func main() {
 		ToolchainVuln struct {
 			AnnouncementPassword string
 		}
+		ProxyCheck struct {
+			Watchlist      []string
+			SampleInterval int64
+			Proxies        []string
+		}
+		ModRepro struct {
+			CacheDir string
+		}
+		PathCheck struct {
+			Allowlist []string
+		}
+		AuthorizationLog struct {
+			SignerKey string // note signer key, named after the log's origin
+		}
+		OIDCIssuers                []oidc.Issuer // trusted issuers of identity tokens for keyless authorization
+		LegacyAuthorizationsCutoff time.Time     // legacy (version 0) authorization submissions are rejected from this time on, if non-zero
 	}
 	if err := json.Unmarshal(configData, &cfg); err != nil {
 		log.Fatal(err)
@@ -123,6 +161,18 @@ func main() {
 	toolchain.LambdaArch = cfg.Toolchain.LambdaArch
 	toolchain.LambdaFunc = cfg.Toolchain.LambdaFunc
 	toolchainvuln.AnnouncementPassword = cfg.ToolchainVuln.AnnouncementPassword
+	proxycheck.Watchlist = cfg.ProxyCheck.Watchlist
+	proxycheck.SampleInterval = cfg.ProxyCheck.SampleInterval
+	proxycheck.Proxies = cfg.ProxyCheck.Proxies
+	modrepro.CacheDir = cfg.ModRepro.CacheDir
+	pathcheck.Allowlist = cfg.PathCheck.Allowlist
+	oidc.Issuers = cfg.OIDCIssuers
+	modules.LegacyCutoff = cfg.LegacyAuthorizationsCutoff
+	if cfg.AuthorizationLog.SignerKey != "" {
+		if err := authlog.SetSignerKey(cfg.AuthorizationLog.SignerKey); err != nil {
+			log.Fatalf("invalid AuthorizationLog.SignerKey: %s", err)
+		}
+	}
 
 	if listen := slices.Concat(cfg.Listen, flags.listen); len(listen) > 0 {
 		listeners, err := listener.OpenAll(listen)
@@ -147,6 +197,30 @@ func main() {
 	if flags.telemetry {
 		go refreshTelemetryCounters()
 	}
+	if flags.gomod {
+		go indexGoMods()
+	}
+	if flags.proxycheck {
+		go checkProxies()
+	}
+	if flags.origin {
+		go refreshOrigins()
+	}
+	if flags.modrepro {
+		go reproduceModules()
+	}
+	if flags.pathcheck {
+		go checkModulePaths()
+	}
+	if flags.activity {
+		go scanActivity()
+	}
+	if flags.webhooks {
+		go notifyWebhooks()
+	}
+	if flags.ownership {
+		go reverifyKeys()
+	}
 
 	go syncToolchainVulns()
 
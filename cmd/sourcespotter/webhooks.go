@@ -0,0 +1,89 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/webhooks"
+)
+
+func notifyWebhooks() {
+	const retryInterval = 1 * time.Minute
+
+	listener := pq.NewListener(sourcespotter.DBAddress, 5*time.Second, 2*time.Minute, nil)
+	if err := listener.Listen(dbChannelName); err != nil {
+		log.Fatal(err)
+	}
+
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		if err := webhooks.NotifyAll(context.Background()); err != nil {
+			log.Printf("error notifying webhooks: %s", err)
+		}
+		waitForWebhookWork(listener, ticker.C)
+	}
+}
+
+// waitForWebhookWork returns when new records have been verified, or when
+// it's time to retry failed deliveries
+func waitForWebhookWork(listener *pq.Listener, retry <-chan time.Time) {
+	for {
+		select {
+		case <-retry:
+			// ping server to force a reconnection if connection is broken
+			listener.Ping()
+			return
+		case n := <-listener.Notify:
+			if isNewPosition(n) {
+				return
+			}
+		}
+	}
+}
+
+// isNewPosition reports whether n announces newly verified records.  A nil
+// notification means the connection was re-established, so we may have
+// missed one.
+func isNewPosition(n *pq.Notification) bool {
+	if n == nil {
+		return true
+	}
+	var payload struct {
+		Event string
+	}
+	if err := json.Unmarshal([]byte(n.Extra), &payload); err != nil {
+		log.Printf("Ignoring malformed database notification: %s", err)
+		return false
+	}
+	return payload.Event == "new_position"
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package gosum
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// Check verifies that gosum consists of well-formed go.sum lines with h1: hashes
+func Check(gosum string) error {
+	if gosum == "" {
+		return fmt.Errorf("go.sum is empty")
+	}
+	if !strings.HasSuffix(gosum, "\n") {
+		return fmt.Errorf("go.sum does not end with a newline")
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(gosum, "\n"), "\n") {
+		if err := checkLine(line); err != nil {
+			return fmt.Errorf("go.sum line %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func checkLine(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return fmt.Errorf("expected three fields")
+	}
+	version, _ := strings.CutSuffix(fields[1], "/go.mod")
+	if err := module.Check(fields[0], version); err != nil {
+		return err
+	}
+	hash, ok := strings.CutPrefix(fields[2], "h1:")
+	if !ok {
+		return fmt.Errorf("hash is not an h1: hash")
+	}
+	if sum, err := base64.StdEncoding.DecodeString(hash); err != nil || len(sum) != sha256.Size {
+		return fmt.Errorf("malformed h1: hash")
+	}
+	return nil
+}
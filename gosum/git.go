@@ -33,35 +33,230 @@ import (
 	"io"
 	"io/fs"
 	"os"
-	"path/filepath"
+	"os/exec"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 	"golang.org/x/mod/sumdb/dirhash"
 	modzip "golang.org/x/mod/zip"
 )
 
-// CreateFromGitTag creates a go.sum file with entries for a tag in a Git repository.
+// CreateFromGitTag creates a go.sum file with entries for a tag in a Git
+// repository.  The tag is resolved to a module version the way the go
+// command does, using the module path from the go.mod file at the tag.
 func CreateFromGitTag(repoRoot string, tag string) (string, error) {
-	subdir, version, err := parseTag(tag)
+	return CreateFromGitTagForModule(repoRoot, tag, "")
+}
+
+// CreateFromGitTagForModule is like CreateFromGitTag, but for the module with
+// the given path, which must match the go.mod file if there is one.  The path
+// is required if there is no go.mod file at the tag, in which case versions
+// v2 and higher are +incompatible.
+func CreateFromGitTagForModule(repoRoot, tag, modulePath string) (string, error) {
+	if !IsVersionTag(tag) {
+		return "", fmt.Errorf("tag %q is not a module version: it must be vX.Y.Z or subdir/vX.Y.Z", tag)
+	}
+	prefix, version, _ := parseTag(tag)
+	commit, err := gitCommit(repoRoot, "refs/tags/"+tag)
+	if err != nil {
+		return "", err
+	}
+	subdir, mod, err := resolveTag(repoRoot, commit, prefix, version, modulePath)
+	if err != nil {
+		return "", fmt.Errorf("tag %s: %w", tag, err)
+	}
+
+	zipHash, gomodHash, err := HashRevision(repoRoot, commit, subdir, mod.Path, mod.Version)
+	if err != nil {
+		return "", err
+	}
+	return formatLines(mod, zipHash, gomodHash), nil
+}
+
+// CreateFromGitRevision creates a go.sum file with entries for a
+// pseudo-version of the module in subdirectory dir (empty for the root) of
+// a Git repository, at an untagged revision.  As with the go command, the
+// pseudo-version is based on the highest tagged version of the module which
+// is an ancestor of the revision.  modulePath is handled as in
+// CreateFromGitTagForModule.
+func CreateFromGitRevision(repoRoot, dir, revision, modulePath string) (string, error) {
+	commit, err := gitCommit(repoRoot, revision)
+	if err != nil {
+		return "", err
+	}
+	gomodPath, hasGoMod, err := gitModulePath(repoRoot, commit, dir)
+	if err != nil {
+		return "", err
+	}
+	if hasGoMod {
+		if modulePath != "" && modulePath != gomodPath {
+			return "", fmt.Errorf("go.mod declares module %s, not %s", gomodPath, modulePath)
+		}
+		modulePath = gomodPath
+	} else if modulePath == "" {
+		return "", fmt.Errorf("%s has no go.mod file at %s, so the module path must be specified", dirName(dir), revision)
+	}
+	_, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return "", fmt.Errorf("invalid module path %q", modulePath)
+	}
+
+	// Tags for a module in a major subdirectory, like v2/, don't include the subdirectory
+	prefix := dir
+	if strings.HasPrefix(pathMajor, "/") && path.Base(dir) == pathMajor[1:] {
+		prefix = strings.TrimSuffix(path.Dir(dir), ".")
+	}
+	base, err := latestAncestorVersion(repoRoot, commit, prefix, pathMajor, !hasGoMod)
+	if err != nil {
+		return "", err
+	}
+	commitTime, err := gitCommitTime(repoRoot, commit)
 	if err != nil {
 		return "", err
 	}
-	moduleDir := filepath.Join(repoRoot, subdir)
-	modulePath, err := modulePathFromFile(filepath.Join(moduleDir, "go.mod"))
+	mod := module.Version{
+		Path:    modulePath,
+		Version: module.PseudoVersion(module.PathMajorPrefix(pathMajor), base, commitTime, commit[:12]),
+	}
+	if err := module.Check(mod.Path, mod.Version); err != nil {
+		return "", err
+	}
+
+	zipHash, gomodHash, err := HashRevision(repoRoot, commit, dir, mod.Path, mod.Version)
 	if err != nil {
 		return "", err
 	}
+	return formatLines(mod, zipHash, gomodHash), nil
+}
+
+// VersionTags returns the tags in a Git repository which name module
+// versions, as determined by IsVersionTag
+func VersionTags(repoRoot string) ([]string, error) {
+	output, err := gitOutput(repoRoot, "tag", "--list")
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, tag := range strings.Fields(output) {
+		if IsVersionTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// resolveTag returns the subdirectory containing the module, and the module
+// version, for a tag of the form prefix/version at commit
+func resolveTag(repoRoot, commit, prefix, version, modulePath string) (string, module.Version, error) {
+	// A module with a major version suffix may be in a major subdirectory, like v2/
+	if major := semver.Major(version); major != "v0" && major != "v1" {
+		subdir := path.Join(prefix, major)
+		gomodPath, ok, err := gitModulePath(repoRoot, commit, subdir)
+		if err != nil {
+			return "", module.Version{}, err
+		}
+		if ok && strings.HasSuffix(gomodPath, "/"+major) && (modulePath == "" || modulePath == gomodPath) {
+			mod := module.Version{Path: gomodPath, Version: version}
+			return subdir, mod, checkModule(mod, prefix)
+		}
+	}
+
+	gomodPath, ok, err := gitModulePath(repoRoot, commit, prefix)
+	if err != nil {
+		return "", module.Version{}, err
+	}
+	if ok {
+		if modulePath != "" && modulePath != gomodPath {
+			return "", module.Version{}, fmt.Errorf("go.mod declares module %s, not %s", gomodPath, modulePath)
+		}
+		mod := module.Version{Path: gomodPath, Version: version}
+		return prefix, mod, checkModule(mod, prefix)
+	}
 
-	zipHash, gomodHash, err := hashModuleZip(repoRoot, tag, subdir, modulePath, version)
+	if modulePath == "" {
+		return "", module.Version{}, fmt.Errorf("%s has no go.mod file, so the module path must be specified", dirName(prefix))
+	}
+	_, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return "", module.Version{}, fmt.Errorf("invalid module path %q", modulePath)
+	}
+	if strings.HasPrefix(pathMajor, "/") {
+		return "", module.Version{}, fmt.Errorf("module path %s has a major version suffix, so it must have a go.mod file", modulePath)
+	}
+	// Like the go command, treat v2+ versions of modules without go.mod as +incompatible
+	if major := semver.Major(version); pathMajor == "" && major != "v0" && major != "v1" {
+		version += "+incompatible"
+	}
+	mod := module.Version{Path: modulePath, Version: version}
+	return prefix, mod, checkModule(mod, prefix)
+}
+
+// checkModule checks that mod's version is compatible with its path's major
+// version suffix, and that its path ends with the tag prefix (the
+// module's subdirectory in the repository)
+func checkModule(mod module.Version, prefix string) error {
+	if err := module.Check(mod.Path, mod.Version); err != nil {
+		return err
+	}
+	pathPrefix, _, _ := module.SplitPathVersion(mod.Path)
+	if prefix != "" && !strings.HasSuffix(pathPrefix, "/"+prefix) {
+		return fmt.Errorf("module path %s does not end with the tag prefix %s", mod.Path, prefix)
+	}
+	return nil
+}
+
+// latestAncestorVersion returns the highest version tagged with prefix at an
+// ancestor of commit (including commit itself), which is compatible with
+// pathMajor, or the empty string if there is none
+func latestAncestorVersion(repoRoot, commit, prefix, pathMajor string, allowIncompatible bool) (string, error) {
+	output, err := gitOutput(repoRoot, "tag", "--merged", commit)
 	if err != nil {
 		return "", err
 	}
+	latest := ""
+	for _, tag := range strings.Fields(output) {
+		tagPrefix, version, err := parseTag(tag)
+		if err != nil || tagPrefix != prefix || !IsVersionTag(tag) {
+			continue
+		}
+		if module.CheckPathMajor(version, pathMajor) != nil {
+			if !allowIncompatible || pathMajor != "" {
+				continue
+			}
+			version += "+incompatible"
+		}
+		if latest == "" || semver.Compare(version, latest) > 0 {
+			latest = version
+		}
+	}
+	return latest, nil
+}
+
+func dirName(dir string) string {
+	if dir == "" {
+		return "the repository root"
+	}
+	return dir
+}
 
-	gosum := fmt.Sprintf("%s %s %s\n", modulePath, version, zipHash)
-	gosum += fmt.Sprintf("%s %s/go.mod %s\n", modulePath, version, gomodHash)
-	return gosum, nil
+// formatLines returns the go.sum lines for a module version with the given hashes.
+func formatLines(mod module.Version, zipHash, gomodHash string) string {
+	gosum := fmt.Sprintf("%s %s %s\n", mod.Path, mod.Version, zipHash)
+	gosum += fmt.Sprintf("%s %s/go.mod %s\n", mod.Path, mod.Version, gomodHash)
+	return gosum
+}
+
+// IsVersionTag reports whether tag names a module version, either vX.Y.Z
+// or subdir/vX.Y.Z for a module in a subdirectory.  Tags that are not in
+// canonical semver form, such as v1.2, are not module versions.
+func IsVersionTag(tag string) bool {
+	_, version, err := parseTag(tag)
+	return err == nil && semver.IsValid(version) && semver.Canonical(version) == version
 }
 
 func parseTag(tag string) (string, string, error) {
@@ -80,7 +275,9 @@ func parseTag(tag string) (string, string, error) {
 	return subdir, version, nil
 }
 
-func hashModuleZip(repoRoot, revision, subdir, modulePath, version string) (string, string, error) {
+// HashRevision returns the h1: hashes of the module zip and go.mod file for
+// the given module version, built from subdir of a revision in a Git repository.
+func HashRevision(repoRoot, revision, subdir, modulePath, version string) (string, string, error) {
 	tempFile, err := os.CreateTemp("", "sourcespotter-authorize-*.zip")
 	if err != nil {
 		return "", "", err
@@ -95,11 +292,16 @@ func hashModuleZip(repoRoot, revision, subdir, modulePath, version string) (stri
 	if err := tempFile.Close(); err != nil {
 		return "", "", err
 	}
-	zipHash, err := dirhash.HashZip(tempFile.Name(), dirhash.Hash1)
+	return hashZip(tempFile.Name(), modVersion)
+}
+
+// hashZip returns the h1: hashes of a module zip file and the go.mod file within it.
+func hashZip(zipfile string, mod module.Version) (string, string, error) {
+	zipHash, err := dirhash.HashZip(zipfile, dirhash.Hash1)
 	if err != nil {
 		return "", "", err
 	}
-	gomodHash, err := hashGoMod(tempFile.Name(), modVersion, dirhash.Hash1)
+	gomodHash, err := hashGoMod(zipfile, mod, dirhash.Hash1)
 	if err != nil {
 		return "", "", err
 	}
@@ -130,7 +332,11 @@ func modulePathFromFile(path string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	mod, err := modfile.Parse(path, content, nil)
+	return modulePathFromData(path, content)
+}
+
+func modulePathFromData(path string, content []byte) (string, error) {
+	mod, err := modfile.ParseLax(path, content, nil)
 	if err != nil {
 		return "", err
 	}
@@ -139,3 +345,61 @@ func modulePathFromFile(path string) (string, error) {
 	}
 	return mod.Module.Mod.Path, nil
 }
+
+// gitModulePath returns the module path from dir/go.mod at commit, and
+// false if there is no such file
+func gitModulePath(repoRoot, commit, dir string) (string, bool, error) {
+	gomod := path.Join(dir, "go.mod")
+	listing, err := gitOutput(repoRoot, "ls-tree", "--name-only", commit, "--", gomod)
+	if err != nil {
+		return "", false, err
+	}
+	if strings.TrimSpace(listing) != gomod {
+		return "", false, nil
+	}
+	content, err := gitOutput(repoRoot, "cat-file", "blob", commit+":"+gomod)
+	if err != nil {
+		return "", false, err
+	}
+	modulePath, err := modulePathFromData(gomod, []byte(content))
+	if err != nil {
+		return "", false, err
+	}
+	return modulePath, true, nil
+}
+
+// gitCommit returns the full hash of the commit that revision refers to
+func gitCommit(repoRoot, revision string) (string, error) {
+	output, err := gitOutput(repoRoot, "rev-parse", "--verify", "--end-of-options", revision+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %s", revision)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+func gitCommitTime(repoRoot, commit string) (time.Time, error) {
+	output, err := gitOutput(repoRoot, "show", "-s", "--format=%ct", commit)
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected commit time %q", output)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+func gitOutput(repoRoot string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoRoot
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,292 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package gosum
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRepo is a fixture Git repository
+type testRepo struct {
+	t    *testing.T
+	dir  string
+	time time.Time // commit time of the next commit
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := &testRepo{t: t, dir: t.TempDir(), time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	repo.git("init", "-q")
+	return repo
+}
+
+func (repo *testRepo) git(args ...string) string {
+	repo.t.Helper()
+	date := repo.time.Format(time.RFC3339)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repo.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_CONFIG_SYSTEM="+os.DevNull,
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		repo.t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commit writes files (deleting those with empty content), commits them,
+// and tags the commit with tags
+func (repo *testRepo) commit(files map[string]string, tags ...string) string {
+	repo.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(repo.dir, filepath.FromSlash(name))
+		if content == "" {
+			if err := os.Remove(path); err != nil {
+				repo.t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			repo.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			repo.t.Fatal(err)
+		}
+	}
+	repo.git("add", "-A")
+	repo.git("commit", "-q", "--allow-empty", "-m", "commit")
+	repo.time = repo.time.Add(time.Hour)
+	for _, tag := range tags {
+		repo.git("tag", tag)
+	}
+	return repo.git("rev-parse", "HEAD")
+}
+
+// checkout returns a directory containing subdir of the repository at revision
+func (repo *testRepo) checkout(revision, subdir string) string {
+	repo.t.Helper()
+	dir := repo.t.TempDir()
+	archive := filepath.Join(repo.t.TempDir(), "archive.tar")
+	repo.git("archive", "--format=tar", "--output="+archive, revision)
+	if output, err := exec.Command("tar", "-xf", archive, "-C", dir).CombinedOutput(); err != nil {
+		repo.t.Fatalf("tar: %s\n%s", err, output)
+	}
+	return filepath.Join(dir, filepath.FromSlash(subdir))
+}
+
+// goSumVersion returns the module path and version of the first go.sum line
+func goSumVersion(goSum string) string {
+	fields := strings.Fields(goSum)
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[0] + "@" + fields[1]
+}
+
+func TestCreateFromGitTag(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit(map[string]string{
+		"go.mod":     "module example.com/repo\n",
+		"repo.go":    "package repo\n",
+		"sub/go.mod": "module example.com/repo/sub\n",
+		"sub/sub.go": "package sub\n",
+	}, "v1.0.0", "sub/v1.0.0", "release/v1.0.0")
+	repo.commit(map[string]string{"repo.go": "package repo // v2 without a suffix\n"}, "v2.0.0")
+	repo.commit(map[string]string{"go.mod": "module example.com/repo/v2\n"}, "v2.0.1")
+	repo.commit(map[string]string{
+		"v3/go.mod":  "module example.com/repo/v3\n",
+		"v3/repo.go": "package repo\n",
+	}, "v3.0.0", "v4.0.0")
+	if tags, err := VersionTags(repo.dir); err != nil {
+		t.Fatal(err)
+	} else if got, want := strings.Join(tags, " "), "release/v1.0.0 sub/v1.0.0 v1.0.0 v2.0.0 v2.0.1 v3.0.0 v4.0.0"; got != want {
+		t.Errorf("VersionTags returned %q, want %q", got, want)
+	}
+	// The working tree's go.mod doesn't matter
+	if err := os.WriteFile(filepath.Join(repo.dir, "go.mod"), []byte("module example.com/other\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tag     string
+		want    string // module@version, or empty if an error is expected
+		workDir string // directory which should hash the same as the tag
+	}{
+		{"v1.0.0", "example.com/repo@v1.0.0", ""},
+		{"sub/v1.0.0", "example.com/repo/sub@v1.0.0", "sub"},
+		{"release/v1.0.0", "", ""}, // go.mod at the prefix doesn't exist
+		{"v2.0.0", "", ""},         // go.mod path lacks /v2, so +incompatible is not allowed
+		{"v2.0.1", "example.com/repo/v2@v2.0.1", ""},
+		{"v3.0.0", "example.com/repo/v3@v3.0.0", "v3"}, // major subdirectory
+		{"v4.0.0", "", ""},                             // neither go.mod is for v4
+		{"v1.0", "", ""},
+	}
+	for _, test := range tests {
+		goSum, err := CreateFromGitTag(repo.dir, test.tag)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("CreateFromGitTag(%q) succeeded with %q, want error", test.tag, goSum)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("CreateFromGitTag(%q) returned error: %s", test.tag, err)
+			continue
+		}
+		if got := goSumVersion(goSum); got != test.want {
+			t.Errorf("CreateFromGitTag(%q) returned %s, want %s", test.tag, got, test.want)
+		}
+		if err := Check(goSum); err != nil {
+			t.Errorf("CreateFromGitTag(%q) returned malformed go.sum: %s", test.tag, err)
+		}
+		_, version, _ := strings.Cut(test.want, "@")
+		fromDir, err := CreateFromDir(repo.checkout(test.tag, test.workDir), version)
+		if err != nil {
+			t.Errorf("CreateFromDir for %q returned error: %s", test.tag, err)
+		} else if fromDir != goSum {
+			t.Errorf("CreateFromGitTag(%q) = %q, but CreateFromDir = %q", test.tag, goSum, fromDir)
+		}
+	}
+}
+
+func TestCreateFromGitTagIncompatible(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit(map[string]string{"old.go": "package old\n"}, "v1.0.0", "v2.0.0")
+
+	if _, err := CreateFromGitTag(repo.dir, "v2.0.0"); err == nil {
+		t.Errorf("CreateFromGitTag succeeded without a module path")
+	}
+	tests := []struct {
+		tag        string
+		modulePath string
+		want       string
+	}{
+		{"v1.0.0", "example.com/old", "example.com/old@v1.0.0"},
+		{"v2.0.0", "example.com/old", "example.com/old@v2.0.0+incompatible"},
+		{"v2.0.0", "example.com/old/v2", ""},
+		{"v2.0.0", "gopkg.in/old.v2", "gopkg.in/old.v2@v2.0.0"},
+		{"v1.0.0", "gopkg.in/old.v2", ""},
+	}
+	for _, test := range tests {
+		goSum, err := CreateFromGitTagForModule(repo.dir, test.tag, test.modulePath)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("CreateFromGitTagForModule(%q, %q) succeeded, want error", test.tag, test.modulePath)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("CreateFromGitTagForModule(%q, %q) returned error: %s", test.tag, test.modulePath, err)
+		} else if got := goSumVersion(goSum); got != test.want {
+			t.Errorf("CreateFromGitTagForModule(%q, %q) returned %s, want %s", test.tag, test.modulePath, got, test.want)
+		}
+	}
+}
+
+func TestCreateFromGitRevision(t *testing.T) {
+	repo := newTestRepo(t)
+	first := repo.commit(map[string]string{"go.mod": "module example.com/repo\n", "repo.go": "package repo\n"})
+	repo.commit(nil, "v1.2.3", "v2.0.0", "sub/v1.5.0")
+	second := repo.commit(nil)
+	repo.commit(nil, "v1.3.0-rc.1")
+	third := repo.commit(map[string]string{
+		"sub/go.mod": "module example.com/repo/sub\n",
+		"v2/go.mod":  "module example.com/repo/v2\n",
+		"v2/repo.go": "package repo\n",
+	})
+
+	tests := []struct {
+		dir      string
+		revision string
+		want     string
+	}{
+		{"", first, "example.com/repo@v0.0.0-20260102030405-" + first[:12]},
+		{"", second, "example.com/repo@v1.2.4-0.20260102050405-" + second[:12]},
+		{"", third, "example.com/repo@v1.3.0-rc.1.0.20260102070405-" + third[:12]},
+		{"sub", third, "example.com/repo/sub@v1.5.1-0.20260102070405-" + third[:12]},
+		{"v2", third, "example.com/repo/v2@v2.0.1-0.20260102070405-" + third[:12]},
+		{"missing", third, ""},
+	}
+	for _, test := range tests {
+		goSum, err := CreateFromGitRevision(repo.dir, test.dir, test.revision, "")
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("CreateFromGitRevision(%q, %s) succeeded, want error", test.dir, test.revision)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("CreateFromGitRevision(%q, %s) returned error: %s", test.dir, test.revision, err)
+		} else if got := goSumVersion(goSum); got != test.want {
+			t.Errorf("CreateFromGitRevision(%q, %s) returned %s, want %s", test.dir, test.revision, got, test.want)
+		}
+	}
+
+	old := newTestRepo(t)
+	old.commit(map[string]string{"old.go": "package old\n"}, "v2.0.0")
+	commit := old.commit(nil)
+	goSum, err := CreateFromGitRevision(old.dir, "", commit, "example.com/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := goSumVersion(goSum), "example.com/old@v2.0.1-0.20260102040405-"+commit[:12]+"+incompatible"; got != want {
+		t.Errorf("CreateFromGitRevision for module without go.mod returned %s, want %s", got, want)
+	}
+}
+
+func TestIsVersionTag(t *testing.T) {
+	tests := []struct {
+		tag string
+		ok  bool
+	}{
+		{"v1.2.3", true},
+		{"v0.0.1-rc.1", true},
+		{"sub/dir/v2.0.0", true},
+		{"v1.2", false},
+		{"v1.2.3+build", false},
+		{"1.2.3", false},
+		{"release-1", false},
+		{"sub/", false},
+		{"/v1.0.0", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if ok := IsVersionTag(test.tag); ok != test.ok {
+			t.Errorf("IsVersionTag(%q) = %v, want %v", test.tag, ok, test.ok)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package gosum
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+// CreateFromDir creates a go.sum file with entries for the module in dir,
+// as it would be hashed if it were published as the given version.  Every
+// file in dir is included, except those that the go command excludes from
+// module zips, so dir should be a clean checkout.
+func CreateFromDir(dir string, version string) (string, error) {
+	modulePath, err := modulePathFromFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	if err := module.Check(modulePath, version); err != nil {
+		return "", err
+	}
+	mod := module.Version{Path: modulePath, Version: version}
+
+	tempFile, err := os.CreateTemp("", "sourcespotter-authorize-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	if err := modzip.CreateFromDir(tempFile, mod, dir); err != nil {
+		return "", err
+	}
+	if err := tempFile.Close(); err != nil {
+		return "", err
+	}
+	zipHash, gomodHash, err := hashZip(tempFile.Name(), mod)
+	if err != nil {
+		return "", err
+	}
+	return formatLines(mod, zipHash, gomodHash), nil
+}
+
+// CreateFromZip creates a go.sum file with entries for a module zip file,
+// such as one downloaded from a module proxy or built by a release pipeline.
+// The module path and version are taken from the paths of the files in the zip.
+func CreateFromZip(zipfile string) (string, error) {
+	mod, err := zipModuleVersion(zipfile)
+	if err != nil {
+		return "", err
+	}
+	checked, err := modzip.CheckZip(mod, zipfile)
+	if err == nil {
+		err = checked.Err()
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s is not a valid module zip: %w", zipfile, err)
+	}
+	zipHash, gomodHash, err := hashZip(zipfile, mod)
+	if err != nil {
+		return "", err
+	}
+	return formatLines(mod, zipHash, gomodHash), nil
+}
+
+// zipModuleVersion returns the module version whose files are in zipfile,
+// based on the path@version/ prefix of the first file.  The module path may
+// contain slashes, but the version can't.
+func zipModuleVersion(zipfile string) (module.Version, error) {
+	z, err := zip.OpenReader(zipfile)
+	if err != nil {
+		return module.Version{}, err
+	}
+	defer z.Close()
+	if len(z.File) == 0 {
+		return module.Version{}, fmt.Errorf("%s is empty", zipfile)
+	}
+	name := z.File[0].Name
+	at := strings.LastIndex(name, "@")
+	if at == -1 {
+		return module.Version{}, fmt.Errorf("%s is not a module zip: %q is not in a path@version directory", zipfile, name)
+	}
+	version, _, ok := strings.Cut(name[at+1:], "/")
+	if !ok {
+		return module.Version{}, fmt.Errorf("%s is not a module zip: %q is not in a path@version directory", zipfile, name)
+	}
+	mod := module.Version{Path: name[:at], Version: version}
+	if err := module.Check(mod.Path, mod.Version); err != nil {
+		return module.Version{}, fmt.Errorf("%s is not a module zip: %w", zipfile, err)
+	}
+	return mod, nil
+}
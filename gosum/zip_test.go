@@ -0,0 +1,156 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package gosum
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+func writeModule(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCreateFromDirAndZip(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":           "module example.com/foo\n\ngo 1.21\n",
+		"foo.go":           "package foo\n",
+		"internal/bar.go":  "package internal\n",
+		"nested/go.mod":    "module example.com/foo/nested\n",
+		"nested/nested.go": "package nested\n",
+	})
+	mod := module.Version{Path: "example.com/foo", Version: "v1.2.3"}
+
+	fromDir, err := CreateFromDir(dir, mod.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zipHash, err := dirhash.Hash1([]string{
+		mod.Path + "@" + mod.Version + "/foo.go",
+		mod.Path + "@" + mod.Version + "/go.mod",
+		mod.Path + "@" + mod.Version + "/internal/bar.go",
+	}, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, mod.Path+"@"+mod.Version+"/"))))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gomodHash, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, "go.mod"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "example.com/foo v1.2.3 " + zipHash + "\nexample.com/foo v1.2.3/go.mod " + gomodHash + "\n"
+	if fromDir != want {
+		t.Errorf("CreateFromDir returned %q, want %q", fromDir, want)
+	}
+
+	zipfile := filepath.Join(t.TempDir(), "foo.zip")
+	f, err := os.Create(zipfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := modzip.CreateFromDir(f, mod, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fromZip, err := CreateFromZip(zipfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromZip != want {
+		t.Errorf("CreateFromZip returned %q, want %q", fromZip, want)
+	}
+	if err := Check(fromZip); err != nil {
+		t.Errorf("Check(%q) returned %v", fromZip, err)
+	}
+}
+
+func TestCreateFromDirErrors(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/foo/v2\n",
+		"foo.go": "package foo\n",
+	})
+	if _, err := CreateFromDir(dir, "v1.0.0"); err == nil {
+		t.Errorf("CreateFromDir succeeded with a version that doesn't match the major version suffix")
+	}
+	if _, err := CreateFromDir(t.TempDir(), "v1.0.0"); err == nil {
+		t.Errorf("CreateFromDir succeeded without a go.mod file")
+	}
+}
+
+func TestCreateFromZipErrors(t *testing.T) {
+	zipfile := filepath.Join(t.TempDir(), "empty.zip")
+	if err := os.WriteFile(zipfile, []byte("not a zip"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateFromZip(zipfile); err == nil {
+		t.Errorf("CreateFromZip succeeded on a file that isn't a zip")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	const hash = "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+	tests := []struct {
+		gosum string
+		ok    bool
+	}{
+		{"example.com/foo v1.0.0 " + hash + "\nexample.com/foo v1.0.0/go.mod " + hash + "\n", true},
+		{"", false},
+		{"example.com/foo v1.0.0 " + hash, false},
+		{"example.com/foo v1.0.0\n", false},
+		{"example.com/foo 1.0.0 " + hash + "\n", false},
+		{"example.com/foo v1.0.0 h2:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=\n", false},
+		{"example.com/foo v1.0.0 h1:AAAA\n", false},
+		{"example.com/foo/v2 v1.0.0 " + hash + "\n", false},
+	}
+	for _, test := range tests {
+		if err := Check(test.gosum); (err == nil) != test.ok {
+			t.Errorf("Check(%q) returned %v, want ok=%v", test.gosum, err, test.ok)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package activity
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"software.sslmate.com/src/sourcespotter"
+	"src.agwa.name/go-dbutil"
+)
+
+const (
+	maxUnusual = 1000
+	riskPeriod = 90 * 24 * time.Hour // unusual activity counts toward a module's risk for this long
+)
+
+// Unusual is a module version whose publication was unusual
+type Unusual struct {
+	Module     string         `sql:"module" json:"module"`
+	Version    string         `sql:"version" json:"version"`
+	ObservedAt time.Time      `sql:"observed_at" json:"observed_at"`
+	Score      int            `sql:"score" json:"score"`
+	Signals    pq.StringArray `sql:"signals" json:"signals"`
+	Detail     string         `sql:"detail" json:"detail"`
+}
+
+// Risk summarizes the recent unusual activity of a module
+type Risk struct {
+	Module string    `json:"module"`
+	Level  string    `json:"level"` // "none", "low", "medium", or "high"
+	Score  int       `json:"score"` // highest score of recent unusual activity
+	Recent []Unusual `json:"recent"`
+}
+
+// LoadUnusual returns the most recent unusual activity.  If module is
+// non-empty, only activity of that module is returned, or if module ends
+// with a slash, activity of every module with that prefix.
+func LoadUnusual(ctx context.Context, module string) ([]Unusual, error) {
+	var rows []Unusual
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+		SELECT module, version, observed_at, score, signals, detail
+		FROM unusual_activity
+		WHERE ($1 = '' OR module = $1 OR (right($1, 1) = '/' AND starts_with(module, $1)))
+		ORDER BY observed_at DESC
+		LIMIT $2
+	`, module, maxUnusual); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// LoadRisk returns the risk of module, based on its unusual activity
+// in the last 90 days
+func LoadRisk(ctx context.Context, module string) (*Risk, error) {
+	risk := &Risk{Module: module, Recent: []Unusual{}}
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &risk.Recent, `
+		SELECT module, version, observed_at, score, signals, detail
+		FROM unusual_activity
+		WHERE module = $1 AND observed_at >= $2
+		ORDER BY observed_at DESC
+		LIMIT $3
+	`, module, time.Now().Add(-riskPeriod), maxUnusual); err != nil {
+		return nil, err
+	}
+	for _, u := range risk.Recent {
+		risk.Score = max(risk.Score, u.Score)
+	}
+	risk.Level = riskLevel(risk.Score)
+	return risk, nil
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package activity flags unusual publishing patterns, such as dormant modules suddenly publishing new versions
+package activity
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"software.sslmate.com/src/sourcespotter"
+	"src.agwa.name/go-dbutil"
+)
+
+const scanBatchSize = 10000
+
+type recordRow struct {
+	DBID       int32     `sql:"db_id"`
+	Position   int64     `sql:"position"`
+	Module     string    `sql:"module"`
+	Version    string    `sql:"version"`
+	ObservedAt time.Time `sql:"observed_at"`
+}
+
+// ScanAll evaluates every record which has appeared in the sumdb since the
+// last scan.  The first time it runs for a sumdb, it starts from the sumdb's
+// current position.
+func ScanAll(ctx context.Context) error {
+	var dbIDs []int32
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &dbIDs, `SELECT db_id FROM db ORDER BY db_id`); err != nil {
+		return fmt.Errorf("error querying sumdbs: %w", err)
+	}
+	for _, dbID := range dbIDs {
+		if err := scanDB(ctx, dbID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanDB(ctx context.Context, dbID int32) error {
+	if _, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO activity_scan (db_id, position) SELECT $1, coalesce(max(position), -1) FROM record WHERE db_id = $1 ON CONFLICT DO NOTHING`, dbID); err != nil {
+		return fmt.Errorf("error initializing activity scan: %w", err)
+	}
+	for {
+		var rows []recordRow
+		if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+			SELECT r.db_id, r.position, r.module, r.version, r.observed_at
+			FROM record r
+			JOIN activity_scan s ON s.db_id = r.db_id
+			WHERE r.db_id = $1 AND r.position > s.position
+			ORDER BY r.position
+			LIMIT $2
+		`, dbID, scanBatchSize); err != nil {
+			return fmt.Errorf("error querying new records: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for _, row := range rows {
+			if err := evaluateRecord(ctx, &row); err != nil {
+				return err
+			}
+		}
+		if _, err := sourcespotter.DB.ExecContext(ctx, `UPDATE activity_scan SET position = $2 WHERE db_id = $1`, dbID, rows[len(rows)-1].Position); err != nil {
+			return fmt.Errorf("error updating activity scan position: %w", err)
+		}
+	}
+}
+
+func evaluateRecord(ctx context.Context, row *recordRow) error {
+	var history []observation
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &history, `SELECT version, observed_at FROM record WHERE module = $1 AND db_id = $2 AND position < $3`, row.Module, row.DBID, row.Position); err != nil {
+		return fmt.Errorf("error querying history of %s: %w", row.Module, err)
+	}
+	findings := evaluate(observation{Version: row.Version, ObservedAt: row.ObservedAt}, history)
+	if len(findings) == 0 {
+		return nil
+	}
+	var signals, details []string
+	for _, f := range findings {
+		signals = append(signals, string(f.Signal))
+		details = append(details, f.Detail)
+	}
+	if _, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO unusual_activity (module, version, observed_at, score, signals, detail) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`, row.Module, row.Version, row.ObservedAt, score(findings), pq.Array(signals), strings.Join(details, "; ")); err != nil {
+		return fmt.Errorf("error storing unusual activity for %s@%s: %w", row.Module, row.Version, err)
+	}
+	log.Printf("unusual activity: %s@%s: %s", row.Module, row.Version, strings.Join(details, "; "))
+	return nil
+}
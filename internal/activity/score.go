@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package activity
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+const (
+	dormantPeriod  = 365 * 24 * time.Hour // no versions for this long means a module is dormant
+	burstWindow    = 10 * time.Minute
+	burstSize      = 5                    // this many versions within burstWindow is a burst
+	stalePseudoAge = 180 * 24 * time.Hour // pseudo-versions this much older than the latest version are stale
+)
+
+type signal string
+
+const (
+	dormantSignal     signal = "dormant"
+	burstSignal       signal = "burst"
+	stalePseudoSignal signal = "stale_pseudo"
+)
+
+var signalWeights = map[signal]int{
+	dormantSignal:     50,
+	burstSignal:       30,
+	stalePseudoSignal: 30,
+}
+
+type observation struct {
+	Version    string    `sql:"version"`
+	ObservedAt time.Time `sql:"observed_at"`
+}
+
+type finding struct {
+	Signal signal
+	Detail string
+}
+
+// evaluate returns the signals raised by the observation of v, given the
+// observations of the module's earlier versions
+func evaluate(v observation, history []observation) []finding {
+	var findings []finding
+
+	var latest observation
+	inBurst := 1
+	for _, h := range history {
+		if h.ObservedAt.After(latest.ObservedAt) {
+			latest = h
+		}
+		if !h.ObservedAt.After(v.ObservedAt) && v.ObservedAt.Sub(h.ObservedAt) <= burstWindow {
+			inBurst++
+		}
+	}
+
+	if !latest.ObservedAt.IsZero() {
+		if gap := v.ObservedAt.Sub(latest.ObservedAt); gap >= dormantPeriod {
+			findings = append(findings, finding{dormantSignal, fmt.Sprintf("first version in %d days (previous was %s)", int(gap.Hours()/24), latest.Version)})
+		}
+	}
+	if inBurst >= burstSize {
+		findings = append(findings, finding{burstSignal, fmt.Sprintf("%d versions within %s", inBurst, burstWindow)})
+	}
+	if module.IsPseudoVersion(v.Version) && !latest.ObservedAt.IsZero() {
+		if pseudoTime, err := module.PseudoVersionTime(v.Version); err == nil && latest.ObservedAt.Sub(pseudoTime) >= stalePseudoAge {
+			findings = append(findings, finding{stalePseudoSignal, fmt.Sprintf("pseudo-version is based on a commit from %s, long before %s was observed", pseudoTime.UTC().Format("2006-01-02"), latest.Version)})
+		}
+	}
+	return findings
+}
+
+func score(findings []finding) int {
+	total := 0
+	for _, f := range findings {
+		total += signalWeights[f.Signal]
+	}
+	return total
+}
+
+// riskLevel converts the highest recent score of a module into a risk level
+func riskLevel(score int) string {
+	switch {
+	case score >= 60:
+		return "high"
+	case score >= 30:
+		return "medium"
+	case score > 0:
+		return "low"
+	default:
+		return "none"
+	}
+}
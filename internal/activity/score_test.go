@@ -0,0 +1,122 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package activity
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		v       observation
+		history []observation
+		want    []signal
+	}{
+		{
+			name: "first version",
+			v:    observation{"v1.0.0", base},
+		},
+		{
+			name:    "regular release",
+			v:       observation{"v1.1.0", base},
+			history: []observation{{"v1.0.0", base.Add(-30 * 24 * time.Hour)}},
+		},
+		{
+			name:    "dormant",
+			v:       observation{"v1.1.0", base},
+			history: []observation{{"v1.0.0", base.Add(-2 * 365 * 24 * time.Hour)}},
+			want:    []signal{dormantSignal},
+		},
+		{
+			name: "burst",
+			v:    observation{"v1.0.4", base},
+			history: []observation{
+				{"v1.0.0", base.Add(-4 * time.Minute)},
+				{"v1.0.1", base.Add(-3 * time.Minute)},
+				{"v1.0.2", base.Add(-2 * time.Minute)},
+				{"v1.0.3", base.Add(-1 * time.Minute)},
+			},
+			want: []signal{burstSignal},
+		},
+		{
+			name: "spread out",
+			v:    observation{"v1.0.4", base},
+			history: []observation{
+				{"v1.0.0", base.Add(-4 * time.Hour)},
+				{"v1.0.1", base.Add(-3 * time.Hour)},
+				{"v1.0.2", base.Add(-2 * time.Hour)},
+				{"v1.0.3", base.Add(-1 * time.Hour)},
+			},
+		},
+		{
+			name:    "stale pseudo-version",
+			v:       observation{"v1.2.1-0.20230101000000-abcdefabcdef", base},
+			history: []observation{{"v1.2.0", base.Add(-24 * time.Hour)}},
+			want:    []signal{stalePseudoSignal},
+		},
+		{
+			name:    "fresh pseudo-version",
+			v:       observation{"v1.2.1-0.20250531000000-abcdefabcdef", base},
+			history: []observation{{"v1.2.0", base.Add(-24 * time.Hour)}},
+		},
+		{
+			name:    "dormant stale pseudo-version",
+			v:       observation{"v0.0.0-20200101000000-abcdefabcdef", base},
+			history: []observation{{"v0.0.0-20220101000000-012345012345", base.Add(-400 * 24 * time.Hour)}},
+			want:    []signal{dormantSignal, stalePseudoSignal},
+		},
+	}
+	for _, test := range tests {
+		var got []signal
+		for _, f := range evaluate(test.v, test.history) {
+			got = append(got, f.Signal)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: evaluate returned %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRiskLevel(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{0, "none"},
+		{20, "low"},
+		{30, "medium"},
+		{80, "high"},
+	}
+	for _, test := range tests {
+		if got := riskLevel(test.score); got != test.want {
+			t.Errorf("riskLevel(%d) = %q, want %q", test.score, got, test.want)
+		}
+	}
+}
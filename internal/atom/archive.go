@@ -0,0 +1,139 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package atom
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Current is the page number of a feed's subscription document.
+const Current = -1
+
+// Archive divides a feed into RFC 5005 archive documents.  Entries are
+// numbered from zero in chronological order, and archive page p contains
+// entries p*PageSize through (p+1)*PageSize-1.  A page is archived once it
+// is complete, after which its contents do not change.  The subscription
+// document contains the last complete page and any newer entries, so that
+// clients which do not understand RFC 5005 still see recent entries.
+//
+// A page's contents only stay the same if entries are never removed from
+// the feed or inserted before existing entries.  Feeds which do not meet
+// that requirement, for example because they are filtered by mutable state,
+// must set Mutable, so that their pages are served as RFC 5005 paged feed
+// documents, which are neither marked as archives nor cached for longer.
+type Archive struct {
+	URL      string // URL of the subscription document
+	PageSize int
+	Total    int // total number of entries in the feed
+	Mutable  bool
+}
+
+// ParsePage returns the archive page number requested by req's page
+// parameter, or Current if there is none.
+func ParsePage(req *http.Request) (int, error) {
+	param := req.URL.Query().Get("page")
+	if param == "" {
+		return Current, nil
+	}
+	page, err := strconv.Atoi(param)
+	if err != nil || page < 0 {
+		return 0, errors.New("invalid page parameter")
+	}
+	return page, nil
+}
+
+func (a *Archive) completePages() int {
+	return a.Total / a.PageSize
+}
+
+// Range returns the entries [start, end) which belong on the given page,
+// or false if the page has not been archived.
+func (a *Archive) Range(page int) (start, end int, ok bool) {
+	complete := a.completePages()
+	if page == Current {
+		return max(complete-1, 0) * a.PageSize, a.Total, true
+	}
+	if page >= complete {
+		return 0, 0, false
+	}
+	return page * a.PageSize, (page + 1) * a.PageSize, true
+}
+
+// PageURL returns the URL of the given page.
+func (a *Archive) PageURL(page int) string {
+	if page == Current {
+		return a.URL
+	}
+	u, err := url.Parse(a.URL)
+	if err != nil {
+		panic(err)
+	}
+	q := u.Query()
+	q.Set("page", strconv.Itoa(page))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// Apply adds the self and RFC 5005 links for the given page to feed, and
+// marks archive pages as such.  For mutable feeds, the paged feed links
+// "previous" and "next" point to older and newer pages, and "first" to the
+// subscription document.
+func (a *Archive) Apply(feed *Feed, page int) {
+	prevRel, nextRel, currentRel := "prev-archive", "next-archive", "current"
+	if a.Mutable {
+		prevRel, nextRel, currentRel = "previous", "next", "first"
+	}
+	feed.Links = append(feed.Links, Link{Rel: "self", Href: a.PageURL(page)})
+	if page == Current {
+		if prev := a.completePages() - 2; prev >= 0 {
+			feed.Links = append(feed.Links, Link{Rel: prevRel, Href: a.PageURL(prev)})
+		}
+		return
+	}
+	if !a.Mutable {
+		feed.Archive = &Marker{}
+	}
+	feed.Links = append(feed.Links, Link{Rel: currentRel, Href: a.URL})
+	if page > 0 {
+		feed.Links = append(feed.Links, Link{Rel: prevRel, Href: a.PageURL(page - 1)})
+	}
+	if page+1 < a.completePages() {
+		feed.Links = append(feed.Links, Link{Rel: nextRel, Href: a.PageURL(page + 1)})
+	}
+}
+
+// CacheControl returns the Cache-Control header for the given page.
+// Archive pages are cached for longer than the subscription document,
+// unless the feed is mutable.
+func (a *Archive) CacheControl(page int, maxAge int) string {
+	if page != Current && !a.Mutable {
+		maxAge = max(maxAge, 86400)
+	}
+	return "public, max-age=" + strconv.Itoa(maxAge) + ", must-revalidate"
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package atom
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestArchiveRange(t *testing.T) {
+	tests := []struct {
+		total      int
+		page       int
+		start, end int
+		ok         bool
+	}{
+		{0, Current, 0, 0, true},
+		{0, 0, 0, 0, false},
+		{5, Current, 0, 5, true},
+		{10, Current, 0, 10, true},
+		{10, 0, 0, 10, true},
+		{10, 1, 0, 0, false},
+		{25, Current, 10, 25, true},
+		{25, 0, 0, 10, true},
+		{25, 1, 10, 20, true},
+		{25, 2, 0, 0, false},
+		{30, Current, 20, 30, true},
+	}
+	for _, test := range tests {
+		a := Archive{URL: "https://example.com/feed.atom", PageSize: 10, Total: test.total}
+		start, end, ok := a.Range(test.page)
+		if start != test.start || end != test.end || ok != test.ok {
+			t.Errorf("Range(%d) with %d entries = %d, %d, %v; want %d, %d, %v", test.page, test.total, start, end, ok, test.start, test.end, test.ok)
+		}
+	}
+}
+
+func linkMap(feed *Feed) map[string]string {
+	links := make(map[string]string)
+	for _, link := range feed.Links {
+		links[link.Rel] = link.Href
+	}
+	return links
+}
+
+func TestArchiveApply(t *testing.T) {
+	a := Archive{URL: "https://example.com/feed.atom?module=example.com%2F", PageSize: 10, Total: 45}
+
+	var current Feed
+	a.Apply(&current, Current)
+	if links := linkMap(&current); links["self"] != a.URL || links["prev-archive"] != a.URL+"&page=2" || len(links) != 2 {
+		t.Errorf("subscription document has links %v", links)
+	}
+	if current.Archive != nil {
+		t.Errorf("subscription document is marked as an archive")
+	}
+
+	var middle Feed
+	a.Apply(&middle, 1)
+	links := linkMap(&middle)
+	if links["self"] != a.URL+"&page=1" || links["current"] != a.URL || links["prev-archive"] != a.URL+"&page=0" || links["next-archive"] != a.URL+"&page=2" {
+		t.Errorf("archive page 1 has links %v", links)
+	}
+
+	var first Feed
+	a.Apply(&first, 0)
+	if _, ok := linkMap(&first)["prev-archive"]; ok {
+		t.Errorf("first archive page has prev-archive link")
+	}
+	var last Feed
+	a.Apply(&last, 3)
+	if _, ok := linkMap(&last)["next-archive"]; ok {
+		t.Errorf("last archive page has next-archive link")
+	}
+
+	data, err := xml.Marshal(middle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `<archive xmlns="http://purl.org/syndication/history/1.0"></archive>`) {
+		t.Errorf("archive page is missing archive element: %s", data)
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		query string
+		page  int
+		ok    bool
+	}{
+		{"", Current, true},
+		{"?page=0", 0, true},
+		{"?page=12", 12, true},
+		{"?page=-1", 0, false},
+		{"?page=x", 0, false},
+	}
+	for _, test := range tests {
+		page, err := ParsePage(httptest.NewRequest("GET", "/feed.atom"+test.query, nil))
+		if page != test.page || (err == nil) != test.ok {
+			t.Errorf("ParsePage(%q) = %d, %v", test.query, page, err)
+		}
+	}
+}
+
+func TestMutableArchive(t *testing.T) {
+	a := Archive{URL: "https://example.com/feed.atom", PageSize: 10, Total: 45, Mutable: true}
+
+	var current Feed
+	a.Apply(&current, Current)
+	if links := linkMap(&current); links["prev-archive"] != "" || links["previous"] != a.URL+"?page=2" {
+		t.Errorf("subscription document has links %v", links)
+	}
+
+	var middle Feed
+	a.Apply(&middle, 1)
+	if middle.Archive != nil {
+		t.Errorf("page of mutable feed is marked as an archive")
+	}
+	if links := linkMap(&middle); links["first"] != a.URL || links["previous"] != a.URL+"?page=0" || links["next"] != a.URL+"?page=2" || len(links) != 4 {
+		t.Errorf("page of mutable feed has links %v", links)
+	}
+
+	if got, want := a.CacheControl(1, 300), "public, max-age=300, must-revalidate"; got != want {
+		t.Errorf("CacheControl for page of mutable feed = %q, want %q", got, want)
+	}
+	a.Mutable = false
+	if got, want := a.CacheControl(1, 300), "public, max-age=86400, must-revalidate"; got != want {
+		t.Errorf("CacheControl for archive page = %q, want %q", got, want)
+	}
+}
@@ -35,10 +35,14 @@ type Feed struct {
 	Title   string   `xml:"title"`
 	Updated string   `xml:"updated"`
 	Author  Person   `xml:"author"`
-	Link    Link     `xml:"link"`
+	Links   []Link   `xml:"link"`
+	Archive *Marker  `xml:"http://purl.org/syndication/history/1.0 archive,omitempty"`
 	Entries []Entry  `xml:"entry"`
 }
 
+// Marker is an empty element whose presence is significant.
+type Marker struct{}
+
 // Entry is an item within a Feed.
 type Entry struct {
 	Title   string  `xml:"title"`
@@ -0,0 +1,179 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package authlog maintains a transparency log of authorization submissions
+package authlog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/sumdb/note"
+	"software.sslmate.com/src/certspotter/merkletree"
+	"src.agwa.name/go-dbutil"
+)
+
+// tileHeight is the height of the log's tiles, which is the same as the
+// Go checksum database's
+const tileHeight = 8
+
+const tileWidth = 1 << tileHeight
+
+var (
+	signer      note.Signer
+	verifierKey string
+)
+
+// SetSignerKey configures the note signer key, as generated by
+// note.GenerateKey, which is used to sign checkpoints.  The key's name is the
+// log's origin.
+func SetSignerKey(skey string) error {
+	s, err := note.NewSigner(skey)
+	if err != nil {
+		return err
+	}
+	// A signer key is PRIVATE+KEY+name+hash+base64(algorithm || seed)
+	fields := strings.SplitN(skey, "+", 5)
+	keyData, err := base64.StdEncoding.DecodeString(fields[4])
+	if err != nil || len(keyData) != 1+ed25519.SeedSize {
+		return errors.New("malformed signer key")
+	}
+	pubkey := ed25519.NewKeyFromSeed(keyData[1:]).Public().(ed25519.PublicKey)
+	vkey, err := note.NewEd25519VerifierKey(s.Name(), pubkey)
+	if err != nil {
+		return err
+	}
+	signer, verifierKey = s, vkey
+	return nil
+}
+
+// hashReader reads the hashes which appear in the log's tiles.  The hash at
+// level L and position N is the root of the complete subtree containing
+// leaves N*tileWidth^L through (N+1)*tileWidth^L-1.
+type hashReader interface {
+	// readHashes returns the hashes at level with positions in [start, end),
+	// stopping at the first one which doesn't exist
+	readHashes(ctx context.Context, level int, start, end uint64) ([]merkletree.Hash, error)
+}
+
+// hashStore stores the hashes which appear in the log's tiles
+type hashStore interface {
+	hashReader
+	writeHash(ctx context.Context, level int, position uint64, hash merkletree.Hash) error
+}
+
+// addLeaf adds leafHash to tree, storing the hash of every subtree which
+// it completes
+func addLeaf(ctx context.Context, store hashStore, tree *merkletree.CollapsedTree, leafHash merkletree.Hash) error {
+	index := tree.Size()
+	if err := tree.Add(leafHash); err != nil {
+		return err
+	}
+	if err := store.writeHash(ctx, 0, index, leafHash); err != nil {
+		return err
+	}
+	for level, n := 1, index+1; n%tileWidth == 0; level, n = level+1, n/tileWidth {
+		children, err := store.readHashes(ctx, level-1, n-tileWidth, n)
+		if err != nil {
+			return err
+		}
+		if len(children) != tileWidth {
+			return fmt.Errorf("log is missing hashes at level %d before position %d", level-1, n)
+		}
+		if err := store.writeHash(ctx, level, n/tileWidth-1, subtreeHash(children)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subtreeHash returns the root of the complete subtree whose children at
+// some level are hashes
+func subtreeHash(hashes []merkletree.Hash) merkletree.Hash {
+	var tree merkletree.CollapsedTree
+	for _, hash := range hashes {
+		tree.Add(hash)
+	}
+	return tree.CalculateRoot()
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type dbHashReader struct {
+	db queryer
+}
+
+func (r dbHashReader) readHashes(ctx context.Context, level int, start, end uint64) ([]merkletree.Hash, error) {
+	var rows []struct {
+		Position uint64 `sql:"position"`
+		Hash     []byte `sql:"hash"`
+	}
+	if err := dbutil.QueryAll(ctx, r.db, &rows, `SELECT position, hash FROM authorization_log_hash WHERE level = $1 AND position >= $2 AND position < $3 ORDER BY position`, level, start, end); err != nil {
+		return nil, err
+	}
+	hashes := make([]merkletree.Hash, 0, len(rows))
+	for i, row := range rows {
+		if row.Position != start+uint64(i) || len(row.Hash) != merkletree.HashLen {
+			break
+		}
+		hashes = append(hashes, merkletree.Hash(row.Hash))
+	}
+	return hashes, nil
+}
+
+type txHashStore struct {
+	dbHashReader
+	tx *sql.Tx
+}
+
+func (s txHashStore) writeHash(ctx context.Context, level int, position uint64, hash merkletree.Hash) error {
+	_, err := s.tx.ExecContext(ctx, `INSERT INTO authorization_log_hash (level, position, hash) VALUES ($1, $2, $3)`, level, position, hash[:])
+	return err
+}
+
+// Append adds entry to the log, within tx, and returns its index.  Appends
+// are serialized by locking the log until tx completes.
+func Append(ctx context.Context, tx *sql.Tx, entry []byte) (uint64, error) {
+	var tree merkletree.CollapsedTree
+	if err := tx.QueryRowContext(ctx, `SELECT tree FROM authorization_log FOR UPDATE`).Scan(dbutil.JSON(&tree)); err != nil {
+		return 0, fmt.Errorf("error loading authorization log: %w", err)
+	}
+	index := tree.Size()
+	if err := addLeaf(ctx, txHashStore{dbHashReader{tx}, tx}, &tree, merkletree.HashLeaf(entry)); err != nil {
+		return 0, fmt.Errorf("error adding entry %d to authorization log: %w", index, err)
+	}
+	if err := dbutil.MustAffectRow(tx.ExecContext(ctx, `UPDATE authorization_log SET tree = $1`, dbutil.JSON(tree))); err != nil {
+		return 0, fmt.Errorf("error updating authorization log: %w", err)
+	}
+	return index, nil
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authlog
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"testing"
+
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/mod/sumdb/tlog"
+	"software.sslmate.com/src/certspotter/merkletree"
+)
+
+type memoryHashStore map[[2]uint64]merkletree.Hash
+
+func (s memoryHashStore) readHashes(ctx context.Context, level int, start, end uint64) ([]merkletree.Hash, error) {
+	var hashes []merkletree.Hash
+	for position := start; position < end; position++ {
+		hash, ok := s[[2]uint64{uint64(level), position}]
+		if !ok {
+			break
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+func (s memoryHashStore) writeHash(ctx context.Context, level int, position uint64, hash merkletree.Hash) error {
+	key := [2]uint64{uint64(level), position}
+	if _, exists := s[key]; exists {
+		return fmt.Errorf("hash at level %d position %d written twice", level, position)
+	}
+	s[key] = hash
+	return nil
+}
+
+type memoryTileReader struct {
+	store memoryHashStore
+}
+
+func (r memoryTileReader) Height() int { return tileHeight }
+
+func (r memoryTileReader) ReadTiles(tiles []tlog.Tile) ([][]byte, error) {
+	data := make([][]byte, len(tiles))
+	for i, tile := range tiles {
+		var err error
+		if data[i], err = readTile(context.Background(), r.store, tile); err != nil {
+			return nil, err
+		}
+		if data[i] == nil {
+			return nil, fmt.Errorf("tile %s not found", tile.Path())
+		}
+	}
+	return data, nil
+}
+
+func (r memoryTileReader) SaveTiles(tiles []tlog.Tile, data [][]byte) {}
+
+func TestTiles(t *testing.T) {
+	ctx := context.Background()
+	store := make(memoryHashStore)
+	var tree merkletree.CollapsedTree
+	var leaves []merkletree.Hash
+	checkSizes := []uint64{1, 2, 255, 256, 257, 513, 65535, 65536, 65536 + 256*3 + 7}
+	for _, size := range checkSizes {
+		for tree.Size() < size {
+			leaf := merkletree.HashLeaf(fmt.Appendf(nil, "entry %d", tree.Size()))
+			if err := addLeaf(ctx, store, &tree, leaf); err != nil {
+				t.Fatal(err)
+			}
+			leaves = append(leaves, leaf)
+		}
+
+		root := tree.CalculateRoot()
+		hashReader := tlog.TileHashReader(tlog.Tree{N: int64(size), Hash: tlog.Hash(root)}, memoryTileReader{store})
+		treeHash, err := tlog.TreeHash(int64(size), hashReader)
+		if err != nil {
+			t.Errorf("size %d: TreeHash failed: %s", size, err)
+			continue
+		}
+		if treeHash != tlog.Hash(root) {
+			t.Errorf("size %d: TreeHash = %x, want %x", size, treeHash, root)
+		}
+		for _, index := range slices.Compact([]uint64{0, size / 2, size - 1}) {
+			proof, err := tlog.ProveRecord(int64(size), int64(index), hashReader)
+			if err != nil {
+				t.Errorf("size %d: ProveRecord(%d) failed: %s", size, index, err)
+				continue
+			}
+			if err := tlog.CheckRecord(proof, int64(size), tlog.Hash(root), int64(index), tlog.Hash(leaves[index])); err != nil {
+				t.Errorf("size %d: CheckRecord(%d) failed: %s", size, index, err)
+			}
+		}
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sourcespotter.example/modules/authorized/log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSignerKey(skey); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { signer, verifierKey = nil, "" }()
+	if verifierKey != vkey {
+		t.Errorf("verifierKey = %q, want %q", verifierKey, vkey)
+	}
+
+	var tree merkletree.CollapsedTree
+	tree.Add(merkletree.HashLeaf([]byte("entry")))
+	checkpoint, err := note.Sign(&note.Note{Text: formatCheckpoint(signer.Name(), &tree)}, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := note.NewVerifier(vkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := note.Open(checkpoint, note.VerifierList(verifier))
+	if err != nil {
+		t.Fatalf("note.Open failed: %s", err)
+	}
+	want := fmt.Sprintf("sourcespotter.example/modules/authorized/log\n1\n%s\n", merkletree.HashLeaf([]byte("entry")).Base64String())
+	if n.Text != want {
+		t.Errorf("checkpoint text = %q, want %q", n.Text, want)
+	}
+}
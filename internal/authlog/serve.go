@@ -0,0 +1,129 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package authlog
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/mod/sumdb/tlog"
+	"software.sslmate.com/src/certspotter/merkletree"
+	"software.sslmate.com/src/sourcespotter"
+	"src.agwa.name/go-dbutil"
+)
+
+// formatCheckpoint returns the text of a checkpoint for tree, in the
+// format used by the Go checksum database and other tiled logs
+func formatCheckpoint(origin string, tree *merkletree.CollapsedTree) string {
+	return fmt.Sprintf("%s\n%d\n%s\n", origin, tree.Size(), tree.CalculateRoot().Base64String())
+}
+
+// readTile returns the contents of tile, or nil if the log does not yet
+// contain it
+func readTile(ctx context.Context, r hashReader, tile tlog.Tile) ([]byte, error) {
+	if tile.H != tileHeight || tile.L < 0 {
+		return nil, nil
+	}
+	start := uint64(tile.N) * tileWidth
+	hashes, err := r.readHashes(ctx, tile.L, start, start+uint64(tile.W))
+	if err != nil {
+		return nil, err
+	}
+	if len(hashes) < tile.W {
+		return nil, nil
+	}
+	data := make([]byte, 0, len(hashes)*merkletree.HashLen)
+	for _, hash := range hashes {
+		data = append(data, hash[:]...)
+	}
+	return data, nil
+}
+
+// ServeCheckpoint serves the latest signed checkpoint of the log
+func ServeCheckpoint(w http.ResponseWriter, req *http.Request) {
+	if signer == nil {
+		http.Error(w, "This Source Spotter instance does not have an authorization log signing key", http.StatusNotFound)
+		return
+	}
+	var tree merkletree.CollapsedTree
+	if err := sourcespotter.DB.QueryRowContext(req.Context(), `SELECT tree FROM authorization_log`).Scan(dbutil.JSON(&tree)); err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	checkpoint, err := note.Sign(&note.Note{Text: formatCheckpoint(signer.Name(), &tree)}, signer)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(checkpoint)
+}
+
+// ServeKey serves the note verifier key of the log's checkpoints
+func ServeKey(w http.ResponseWriter, req *http.Request) {
+	if signer == nil {
+		http.Error(w, "This Source Spotter instance does not have an authorization log signing key", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	fmt.Fprintln(w, verifierKey)
+}
+
+// ServeTile serves a hash tile of the log.  Entries are not served as data
+// tiles, since they can be larger than a tile; they are available from the
+// submissions API instead.
+func ServeTile(w http.ResponseWriter, req *http.Request) {
+	tile, err := tlog.ParseTilePath("tile/" + req.PathValue("path"))
+	if err != nil {
+		http.Error(w, "Invalid tile path", http.StatusBadRequest)
+		return
+	}
+	data, err := readTile(req.Context(), dbHashReader{sourcespotter.DB}, tile)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		http.Error(w, "Tile not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(data)
+}
@@ -36,8 +36,9 @@ type ModuleInfo struct {
 }
 
 type ModuleOrigin struct {
-	VCS  string
-	URL  string
-	Ref  string
-	Hash string
+	VCS    string
+	URL    string
+	Ref    string
+	Hash   string
+	Subdir string
 }
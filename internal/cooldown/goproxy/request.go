@@ -52,6 +52,14 @@ func (r InfoRequest) Path() string { return "@v/" + r.Version.Escaped() + ".info
 func (r ModRequest) Path() string  { return "@v/" + r.Version.Escaped() + ".mod" }
 func (r ZipRequest) Path() string  { return "@v/" + r.Version.Escaped() + ".zip" }
 
+// DefaultProxy is the base URL of the Go Module Mirror.
+const DefaultProxy = "https://proxy.golang.org"
+
+// RequestURL returns the URL of req for the given module on the proxy at proxyURL.
+func RequestURL(proxyURL string, module ModulePath, req Request) string {
+	return strings.TrimSuffix(proxyURL, "/") + "/" + module.Escaped() + "/" + req.Path()
+}
+
 func ParseRequestPath(path string) (ModulePath, Request, error) {
 	if strings.HasSuffix(path, "/@latest") {
 		if modulePath, err := UnescapeModulePath(strings.TrimSuffix(path, "/@latest")); err == nil {
@@ -52,8 +52,7 @@ func loadVersions(ctx context.Context, module goproxy.ModulePath, minAge time.Du
 }
 
 func redirectUpstream(w http.ResponseWriter, module goproxy.ModulePath, req goproxy.Request) {
-	url := "https://proxy.golang.org/" + module.Escaped() + "/" + req.Path()
-	w.Header().Set("Location", url)
+	w.Header().Set("Location", goproxy.RequestURL(goproxy.DefaultProxy, module, req))
 	w.WriteHeader(http.StatusSeeOther)
 }
 
@@ -0,0 +1,91 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package gomod
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	basedashboard "software.sslmate.com/src/sourcespotter/internal/dashboard"
+	"src.agwa.name/go-dbutil"
+)
+
+const maxDashboardDependents = 1000
+
+type errorRow struct {
+	Module     string    `sql:"module"`
+	Version    string    `sql:"version"`
+	InsertedAt time.Time `sql:"inserted_at"`
+	Error      string    `sql:"error"`
+}
+
+type dashboard struct {
+	Domain     string
+	Module     string
+	Version    string
+	Dependents []Dependent
+	Truncated  bool
+	Errors     []errorRow
+}
+
+func loadDashboard(ctx context.Context, module, version string) (*dashboard, error) {
+	dash := &dashboard{
+		Domain:  sourcespotter.Domain,
+		Module:  module,
+		Version: version,
+	}
+	if module != "" {
+		dependents, err := loadDependents(ctx, module, version, maxDashboardDependents+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(dependents) > maxDashboardDependents {
+			dash.Truncated = true
+			dependents = dependents[:maxDashboardDependents]
+		}
+		dash.Dependents = dependents
+	}
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &dash.Errors, `SELECT module, version, inserted_at, error FROM gomod WHERE error IS NOT NULL ORDER BY inserted_at DESC LIMIT 100`); err != nil {
+		return nil, err
+	}
+	return dash, nil
+}
+
+func ServeDashboard(w http.ResponseWriter, req *http.Request) {
+	dash, err := loadDashboard(req.Context(), req.URL.Query().Get("module"), req.URL.Query().Get("version"))
+	if err != nil {
+		log.Printf("error loading dependents dashboard: %s", err)
+		http.Error(w, "Internal Database Error", 500)
+		return
+	}
+	basedashboard.ServePage(w, req,
+		"Reverse Dependencies - Source Spotter",
+		"Find every published Go module version that depends on a module.",
+		"dependents.html", dash)
+}
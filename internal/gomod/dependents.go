@@ -0,0 +1,100 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package gomod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"software.sslmate.com/src/sourcespotter"
+	"src.agwa.name/go-dbutil"
+)
+
+const maxDependents = 10_000
+
+// Dependent is a module version whose go.mod file refers to the queried module.
+type Dependent struct {
+	Module     string `sql:"module"`
+	Version    string `sql:"version"`
+	DepVersion string `sql:"dep_version"` // version of the queried module that is required or substituted
+	Indirect   bool   `sql:"indirect"`
+	Replace    bool   `sql:"replace"` // true if the queried module is the target of a replace directive
+}
+
+// loadDependents returns up to limit module versions which require, or
+// replace another module with, depModule.  If depVersion is non-empty,
+// only dependents which refer to that version are returned.
+func loadDependents(ctx context.Context, depModule, depVersion string, limit int) ([]Dependent, error) {
+	query := `
+		SELECT module, version, dep_version, indirect, FALSE AS replace
+		FROM gomod_require
+		WHERE dep_module = $1 AND ($2 = '' OR dep_version = $2)
+		UNION ALL
+		SELECT module, version, new_version AS dep_version, FALSE AS indirect, TRUE AS replace
+		FROM gomod_replace
+		WHERE new_module = $1 AND ($2 = '' OR new_version = $2)
+		ORDER BY module, version, dep_version
+		LIMIT ` + strconv.Itoa(limit)
+	var dependents []Dependent
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &dependents, query, depModule, depVersion); err != nil {
+		return nil, err
+	}
+	return dependents, nil
+}
+
+func ServeDependents(w http.ResponseWriter, req *http.Request) {
+	module := req.URL.Query().Get("module")
+	if module == "" {
+		http.Error(w, "Missing module parameter", http.StatusBadRequest)
+		return
+	}
+	version := req.URL.Query().Get("version")
+
+	dependents, err := loadDependents(req.Context(), module, version, maxDependents+1)
+	if err != nil {
+		log.Printf("error loading dependents of %s: %s", module, err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	if len(dependents) > maxDependents {
+		http.Error(w, fmt.Sprintf("Sorry, there are more than %d dependents of %s; try specifying a version", maxDependents, module), http.StatusInternalServerError)
+		return
+	}
+	if dependents == nil {
+		dependents = []Dependent{}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dependents)
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package gomod indexes the go.mod file of every module version in the sumdb
+package gomod
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"golang.org/x/sync/errgroup"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/cooldown/goproxy"
+	"software.sslmate.com/src/sourcespotter/internal/httpclient"
+	"software.sslmate.com/src/sourcespotter/internal/proxycheck"
+	"src.agwa.name/go-dbutil"
+)
+
+const (
+	indexBatchSize   = 1000
+	indexConcurrency = 10
+)
+
+type recordRow struct {
+	Module      string `sql:"module"`
+	Version     string `sql:"version"`
+	GomodSHA256 []byte `sql:"gomod_sha256"`
+}
+
+// IndexAll downloads, verifies, and indexes the go.mod file of every record
+// which hasn't been indexed yet.  Versions whose go.mod can't be downloaded
+// because of a transient failure are left unindexed, and retried by the next
+// call.
+func IndexAll(ctx context.Context) error {
+	var after recordRow // continue after this module version, which may have been left unindexed
+	for {
+		var rows []recordRow
+		if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `SELECT DISTINCT ON (module, version) module, version, gomod_sha256 FROM record WHERE (module, version) > ($2, $3) AND NOT EXISTS (SELECT 1 FROM gomod WHERE (gomod.module, gomod.version) = (record.module, record.version)) ORDER BY module, version, db_id, position LIMIT $1`, indexBatchSize, after.Module, after.Version); err != nil {
+			return fmt.Errorf("error querying unindexed records: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		after = rows[len(rows)-1]
+		group, groupCtx := errgroup.WithContext(ctx)
+		group.SetLimit(indexConcurrency)
+		for _, r := range rows {
+			group.Go(func() error {
+				return indexVersion(groupCtx, r.Module, r.Version, r.GomodSHA256)
+			})
+		}
+		if err := group.Wait(); err != nil {
+			return err
+		}
+	}
+}
+
+func recordIndexError(ctx context.Context, module, version string, indexErr error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	errString := indexErr.Error()
+	if _, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO gomod (module, version, error) VALUES ($1, $2, $3)`, module, version, errString); err != nil {
+		return fmt.Errorf("error inserting gomod row for %s@%s with error %q: %w", module, version, errString, err)
+	}
+	return nil
+}
+
+func indexVersion(ctx context.Context, module, version string, gomodSHA256 []byte) error {
+	modulePath, err := goproxy.MakeModulePath(module)
+	if err != nil {
+		return recordIndexError(ctx, module, version, fmt.Errorf("invalid module path: %w", err))
+	}
+	moduleVersion, err := goproxy.MakeModuleVersion(version)
+	if err != nil {
+		return recordIndexError(ctx, module, version, fmt.Errorf("invalid module version: %w", err))
+	}
+	data, err := proxycheck.FetchMod(ctx, goproxy.DefaultProxy, modulePath, moduleVersion, gomodSHA256)
+	if err != nil && httpclient.IsTransient(err) && ctx.Err() == nil {
+		log.Printf("leaving %s@%s unindexed for now: %s", module, version, err)
+		return nil
+	} else if err != nil {
+		return recordIndexError(ctx, module, version, err)
+	}
+
+	mod, err := parseGoMod(module+"@"+version+"/go.mod", data)
+	if err != nil {
+		return recordIndexError(ctx, module, version, fmt.Errorf("error parsing go.mod: %w", err))
+	}
+	return insertGoMod(ctx, module, version, mod)
+}
+
+func insertGoMod(ctx context.Context, module, version string, mod *goMod) error {
+	tx, err := sourcespotter.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting database transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO gomod (module, version, go_version) VALUES ($1, $2, NULLIF($3, ''))`, module, version, mod.GoVersion); err != nil {
+		return fmt.Errorf("error inserting gomod row: %w", err)
+	}
+	for _, r := range mod.Require {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO gomod_require (module, version, dep_module, dep_version, indirect) VALUES ($1,$2,$3,$4,$5)`, module, version, r.Module, r.Version, r.Indirect); err != nil {
+			return fmt.Errorf("error inserting gomod_require row: %w", err)
+		}
+	}
+	for _, r := range mod.Replace {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO gomod_replace (module, version, old_module, old_version, new_module, new_version) VALUES ($1,$2,$3,$4,$5,$6)`, module, version, r.OldModule, r.OldVersion, r.NewModule, r.NewVersion); err != nil {
+			return fmt.Errorf("error inserting gomod_replace row: %w", err)
+		}
+	}
+	for _, r := range mod.Retract {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO gomod_retract (module, version, low, high, rationale) VALUES ($1,$2,$3,$4,$5)`, module, version, r.Low, r.High, r.Rationale); err != nil {
+			return fmt.Errorf("error inserting gomod_retract row: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing database transaction: %w", err)
+	}
+	return nil
+}
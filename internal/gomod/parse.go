@@ -0,0 +1,100 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package gomod
+
+import (
+	"golang.org/x/mod/modfile"
+)
+
+type requireDirective struct {
+	Module   string
+	Version  string
+	Indirect bool
+}
+
+type replaceDirective struct {
+	OldModule  string
+	OldVersion string
+	NewModule  string
+	NewVersion string
+}
+
+type retractDirective struct {
+	Low       string
+	High      string
+	Rationale string
+}
+
+type goMod struct {
+	GoVersion string
+	Require   []requireDirective
+	Replace   []replaceDirective
+	Retract   []retractDirective
+}
+
+// parseGoMod extracts the directives from a go.mod file.  go.mod files
+// written by newer versions of Go may contain directives that we don't
+// understand, so if strict parsing fails, we fall back to lax parsing,
+// which only understands the directives that matter in a dependency
+// (notably, not replace).
+func parseGoMod(filename string, data []byte) (*goMod, error) {
+	file, err := modfile.Parse(filename, data, nil)
+	if err != nil {
+		var laxErr error
+		file, laxErr = modfile.ParseLax(filename, data, nil)
+		if laxErr != nil {
+			return nil, err
+		}
+	}
+
+	mod := new(goMod)
+	if file.Go != nil {
+		mod.GoVersion = file.Go.Version
+	}
+	for _, r := range file.Require {
+		mod.Require = append(mod.Require, requireDirective{
+			Module:   r.Mod.Path,
+			Version:  r.Mod.Version,
+			Indirect: r.Indirect,
+		})
+	}
+	for _, r := range file.Replace {
+		mod.Replace = append(mod.Replace, replaceDirective{
+			OldModule:  r.Old.Path,
+			OldVersion: r.Old.Version,
+			NewModule:  r.New.Path,
+			NewVersion: r.New.Version,
+		})
+	}
+	for _, r := range file.Retract {
+		mod.Retract = append(mod.Retract, retractDirective{
+			Low:       r.Low,
+			High:      r.High,
+			Rationale: r.Rationale,
+		})
+	}
+	return mod, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package gomod
+
+import (
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	data := []byte(`module example.com/mod
+
+go 1.22
+
+require (
+	example.com/direct v1.2.3
+	example.com/indirect v0.1.0 // indirect
+)
+
+replace example.com/direct => example.com/fork v1.2.4
+
+replace example.com/old v1.0.0 => ../local
+
+retract (
+	v1.0.1 // published accidentally
+	[v1.1.0, v1.1.5]
+)
+`)
+	mod, err := parseGoMod("go.mod", data)
+	if err != nil {
+		t.Fatalf("parseGoMod: error: %s", err)
+	}
+	if mod.GoVersion != "1.22" {
+		t.Errorf("GoVersion = %q, want %q", mod.GoVersion, "1.22")
+	}
+
+	wantRequire := []requireDirective{
+		{"example.com/direct", "v1.2.3", false},
+		{"example.com/indirect", "v0.1.0", true},
+	}
+	if len(mod.Require) != len(wantRequire) {
+		t.Fatalf("got %d require directives, want %d", len(mod.Require), len(wantRequire))
+	}
+	for i := range wantRequire {
+		if mod.Require[i] != wantRequire[i] {
+			t.Errorf("Require[%d] = %+v, want %+v", i, mod.Require[i], wantRequire[i])
+		}
+	}
+
+	wantReplace := []replaceDirective{
+		{"example.com/direct", "", "example.com/fork", "v1.2.4"},
+		{"example.com/old", "v1.0.0", "../local", ""},
+	}
+	if len(mod.Replace) != len(wantReplace) {
+		t.Fatalf("got %d replace directives, want %d", len(mod.Replace), len(wantReplace))
+	}
+	for i := range wantReplace {
+		if mod.Replace[i] != wantReplace[i] {
+			t.Errorf("Replace[%d] = %+v, want %+v", i, mod.Replace[i], wantReplace[i])
+		}
+	}
+
+	wantRetract := []retractDirective{
+		{"v1.0.1", "v1.0.1", "published accidentally"},
+		{"v1.1.0", "v1.1.5", ""},
+	}
+	if len(mod.Retract) != len(wantRetract) {
+		t.Fatalf("got %d retract directives, want %d", len(mod.Retract), len(wantRetract))
+	}
+	for i := range wantRetract {
+		if mod.Retract[i] != wantRetract[i] {
+			t.Errorf("Retract[%d] = %+v, want %+v", i, mod.Retract[i], wantRetract[i])
+		}
+	}
+}
+
+func TestParseGoModUnknownDirective(t *testing.T) {
+	data := []byte("module example.com/mod\n\ngo 1.99\n\nfrobnicate example.com/x\n\nrequire example.com/dep v1.0.0\n")
+	mod, err := parseGoMod("go.mod", data)
+	if err != nil {
+		t.Fatalf("parseGoMod: error: %s", err)
+	}
+	if len(mod.Require) != 1 || mod.Require[0].Module != "example.com/dep" {
+		t.Errorf("Require = %+v, want example.com/dep", mod.Require)
+	}
+}
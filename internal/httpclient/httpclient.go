@@ -28,13 +28,84 @@ package httpclient
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
+	"syscall"
 )
 
+// nonPublicPrefixes are special-purpose ranges which IsGlobalUnicast and
+// IsPrivate don't exclude, but which aren't globally reachable, or which
+// embed an IPv4 address that may not be
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space (CGNAT)
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, including Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4
+	netip.MustParsePrefix("3fff::/20"),       // documentation
+	netip.MustParsePrefix("5f00::/16"),       // segment routing
+}
+
+// CheckPublicAddress is a net.Dialer Control function which refuses to
+// connect to loopback, private, or otherwise non-public addresses.  Use it
+// when connecting to hosts chosen by untrusted users.
+func CheckPublicAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	addr := addrPort.Addr().Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return fmt.Errorf("%s is not a public address", addr)
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%s is not a public address", addr)
+		}
+	}
+	return nil
+}
+
+// StatusError is returned, wrapped in a *url.Error, when a server responds
+// with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Status, e.Body)
+}
+
+// IsTransient reports whether err, as returned by this package, may not
+// recur if the request is retried: a network error, a timeout, or a 5xx or
+// 429 status.  Other statuses, such as a 404 or 410 for a file which has
+// been removed, are not transient.
+func IsTransient(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
 func doRequest(req *http.Request) (*http.Response, error) {
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -43,7 +114,7 @@ func doRequest(req *http.Request) (*http.Response, error) {
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		defer resp.Body.Close()
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(respBody))}
+		return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: bytes.TrimSpace(respBody)}}
 	}
 	return resp, nil
 }
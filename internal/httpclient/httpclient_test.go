@@ -0,0 +1,104 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckPublicAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		ok      bool
+	}{
+		{"93.184.215.14:443", true},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", true},
+		{"127.0.0.1:443", false},
+		{"10.1.2.3:443", false},
+		{"169.254.169.254:80", false},
+		{"[::1]:443", false},
+		{"[::ffff:192.168.1.1]:443", false},
+		{"[fd00::1]:443", false},
+		{"100.64.0.1:443", false},
+		{"100.127.255.255:443", false},
+		{"100.128.0.1:443", true},
+		{"192.0.0.8:443", false},
+		{"198.18.0.1:443", false},
+		{"203.0.113.5:443", false},
+		{"[::ffff:100.64.0.1]:443", false},
+		{"[64:ff9b::a01:203]:443", false},
+		{"[64:ff9b:1::1]:443", false},
+		{"[2001:db8::1]:443", false},
+		{"[2002:a01:203::1]:443", false},
+	}
+	for _, test := range tests {
+		if err := CheckPublicAddress("tcp", test.address, nil); (err == nil) != test.ok {
+			t.Errorf("CheckPublicAddress(%q) returned %v", test.address, err)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	var status int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "test", status)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusNotFound, false},
+		{http.StatusGone, false},
+		{http.StatusForbidden, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusServiceUnavailable, true},
+	}
+	for _, test := range tests {
+		status = test.status
+		_, err := DownloadBytes(context.Background(), server.URL)
+		if err == nil {
+			t.Errorf("status %d: DownloadBytes succeeded", test.status)
+		} else if got := IsTransient(err); got != test.want {
+			t.Errorf("status %d: IsTransient(%v) = %v, want %v", test.status, err, got, test.want)
+		}
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if _, err := DownloadBytes(context.Background(), closed.URL); err == nil || !IsTransient(err) {
+		t.Errorf("IsTransient(%v) = false for connection failure", err)
+	}
+	if IsTransient(errors.New("go.mod has unexpected hash")) {
+		t.Errorf("IsTransient = true for error not returned by httpclient")
+	}
+}
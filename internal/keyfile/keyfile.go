@@ -0,0 +1,183 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package keyfile encodes the private key files of sourcespotter-authorize
+package keyfile
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	plaintextHeader = "ed25519"
+	encryptedHeader = "ed25519-encrypted"
+	kdfName         = "scrypt"
+	saltSize        = 16
+	keySize         = 32
+	maxLogN         = 22 // refuse files which would take too long to decrypt
+)
+
+// scryptLogN is the base-2 log of the scrypt cost parameter used when
+// encrypting.  It is stored in the file, so it can be raised without
+// breaking existing files.
+var scryptLogN = 17
+
+// ErrWrongPassphrase is returned by Parse when the passphrase doesn't
+// decrypt the key
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// Marshal returns an unencrypted key file containing priv
+func Marshal(priv ed25519.PrivateKey) []byte {
+	return fmt.Appendf(nil, "%s\n%s\n", plaintextHeader, base64.StdEncoding.EncodeToString(priv))
+}
+
+// MarshalEncrypted returns a key file containing priv, encrypted with
+// AES-256-GCM under a key derived from passphrase with scrypt.  The file
+// looks like:
+//
+//	ed25519-encrypted
+//	scrypt <log2 N> <r> <p> <base64 salt>
+//	<base64 nonce and ciphertext>
+//
+// The first two lines are authenticated as additional data.
+func MarshalEncrypted(priv ed25519.PrivateKey, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("%s\n%s %d %d %d %s\n", encryptedHeader, kdfName, scryptLogN, 8, 1, base64.StdEncoding.EncodeToString(salt))
+	aead, err := newAEAD(passphrase, salt, scryptLogN, 8, 1)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, priv, []byte(header))
+	return fmt.Appendf(nil, "%s%s\n", header, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// IsEncrypted reports whether content is an encrypted key file
+func IsEncrypted(content []byte) bool {
+	header, _, _ := strings.Cut(string(content), "\n")
+	return strings.TrimSpace(header) == encryptedHeader
+}
+
+// Parse parses a key file.  If the file is encrypted, Parse calls
+// passphrase to get the passphrase.
+func Parse(content []byte, passphrase func() ([]byte, error)) (ed25519.PrivateKey, error) {
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	switch strings.TrimSpace(lines[0]) {
+	case plaintextHeader:
+		if len(lines) != 2 {
+			return nil, errors.New("expected two lines")
+		}
+		return decodeKey(strings.TrimSpace(lines[1]))
+	case encryptedHeader:
+		if len(lines) != 3 {
+			return nil, errors.New("expected three lines")
+		}
+		return parseEncrypted(lines, passphrase)
+	default:
+		return nil, errors.New("unsupported algorithm")
+	}
+}
+
+func decodeKey(s string) (ed25519.PrivateKey, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyBytes) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid length")
+	}
+	return ed25519.PrivateKey(keyBytes), nil
+}
+
+func parseEncrypted(lines []string, passphrase func() ([]byte, error)) (ed25519.PrivateKey, error) {
+	fields := strings.Fields(lines[1])
+	if len(fields) != 5 || fields[0] != kdfName {
+		return nil, errors.New("unsupported key derivation function")
+	}
+	logN, err1 := strconv.Atoi(fields[1])
+	r, err2 := strconv.Atoi(fields[2])
+	p, err3 := strconv.Atoi(fields[3])
+	salt, err4 := base64.StdEncoding.DecodeString(fields[4])
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		return nil, fmt.Errorf("invalid scrypt parameters: %w", err)
+	}
+	if logN < 1 || logN > maxLogN || r < 1 || p < 1 || r*p >= 1<<30 {
+		return nil, errors.New("unsupported scrypt parameters")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[2]))
+	if err != nil {
+		return nil, err
+	}
+
+	pass, err := passphrase()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(pass, salt, logN, r, p)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	// Authenticate the header exactly as MarshalEncrypted wrote it
+	header := fmt.Sprintf("%s\n%s\n", encryptedHeader, strings.TrimSpace(lines[1]))
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(header))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if len(plaintext) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid length")
+	}
+	return ed25519.PrivateKey(plaintext), nil
+}
+
+func newAEAD(passphrase, salt []byte, logN, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, 1<<logN, r, p, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package keyfile
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func init() {
+	// Keep the tests fast
+	scryptLogN = 10
+}
+
+func passphrase(s string) func() ([]byte, error) {
+	return func() ([]byte, error) { return []byte(s), nil }
+}
+
+func noPassphrase() ([]byte, error) {
+	return nil, errors.New("passphrase requested")
+}
+
+func TestPlaintext(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	content := Marshal(priv)
+	if IsEncrypted(content) {
+		t.Errorf("IsEncrypted returned true for plaintext file")
+	}
+	parsed, err := Parse(content, noPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equal(priv) {
+		t.Errorf("Parse returned wrong key")
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	content, err := MarshalEncrypted(priv, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(content) {
+		t.Errorf("IsEncrypted returned false for encrypted file")
+	}
+	if strings.Contains(string(content), string(Marshal(priv)[len(plaintextHeader)+1:])) {
+		t.Errorf("encrypted file contains the plaintext key")
+	}
+	parsed, err := Parse(content, passphrase("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equal(priv) {
+		t.Errorf("Parse returned wrong key")
+	}
+	if _, err := Parse(content, passphrase("battery staple")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Parse with wrong passphrase returned %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	encrypted, err := MarshalEncrypted(priv, []byte("pass"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(encrypted), "\n")
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"unknown algorithm", "rsa\nAAAA\n"},
+		{"short key", "ed25519\nAAAA\n"},
+		{"missing ciphertext", lines[0] + "\n" + lines[1] + "\n"},
+		{"unknown kdf", lines[0] + "\n" + strings.Replace(lines[1], "scrypt", "argon2id", 1) + "\n" + lines[2] + "\n"},
+		{"expensive kdf", lines[0] + "\n" + strings.Replace(lines[1], "scrypt 10 ", "scrypt 30 ", 1) + "\n" + lines[2] + "\n"},
+		{"tampered parameters", lines[0] + "\n" + strings.Replace(lines[1], "scrypt 10 8 1", "scrypt 10 8 2", 1) + "\n" + lines[2] + "\n"},
+	}
+	for _, test := range tests {
+		if _, err := Parse([]byte(test.content), passphrase("pass")); err == nil {
+			t.Errorf("%s: Parse succeeded", test.name)
+		}
+	}
+}
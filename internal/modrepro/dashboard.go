@@ -0,0 +1,91 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modrepro
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	basedashboard "software.sslmate.com/src/sourcespotter/internal/dashboard"
+	"src.agwa.name/go-dbutil"
+)
+
+type failureRow struct {
+	Module    string    `sql:"module"`
+	Version   string    `sql:"version"`
+	CheckedAt time.Time `sql:"checked_at"`
+	Status    string    `sql:"status"`
+	Message   string    `sql:"message"`
+}
+
+type dashboard struct {
+	Domain     string
+	EqualCount int64
+	Unequal    []failureRow
+	Failures   []failureRow
+}
+
+func loadFailures(ctx context.Context, status reproStatus, limit int) ([]failureRow, error) {
+	var rows []failureRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `SELECT module, version, checked_at, status, coalesce(message,'') AS message FROM module_repro WHERE status = $1 ORDER BY checked_at DESC LIMIT $2`, status, limit); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func loadDashboard(ctx context.Context) (*dashboard, error) {
+	dash := &dashboard{Domain: sourcespotter.Domain}
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT count(*) FROM module_repro WHERE status = 'equal'`).Scan(&dash.EqualCount); err != nil {
+		return nil, err
+	}
+	if rows, err := loadFailures(ctx, reproUnequal, 1000); err == nil {
+		dash.Unequal = rows
+	} else {
+		return nil, err
+	}
+	if rows, err := loadFailures(ctx, reproFailed, 100); err == nil {
+		dash.Failures = rows
+	} else {
+		return nil, err
+	}
+	return dash, nil
+}
+
+func ServeDashboard(w http.ResponseWriter, req *http.Request) {
+	dash, err := loadDashboard(req.Context())
+	if err != nil {
+		log.Printf("error loading modrepro dashboard: %s", err)
+		http.Error(w, "Internal Database Error", 500)
+		return
+	}
+	basedashboard.ServePage(w, req,
+		"Module Reproducibility - Source Spotter",
+		"Source Spotter verifies that module versions in the Go Checksum Database can be reproduced from their source repository.",
+		"modrepro.html", dash)
+}
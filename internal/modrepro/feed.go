@@ -0,0 +1,84 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modrepro
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/atom"
+)
+
+func ServeFailuresAtom(w http.ResponseWriter, req *http.Request) {
+	rows, err := loadFailures(req.Context(), reproUnequal, 1000)
+	if err != nil {
+		log.Printf("error querying module reproducibility failures: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+
+	feedURL := "https://feeds.api." + sourcespotter.Domain + "/modrepro/failures.atom"
+	feed := atom.Feed{
+		Xmlns:  "http://www.w3.org/2005/Atom",
+		ID:     feedURL,
+		Title:  "Module Reproducibility Failures",
+		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
+		Links:  []atom.Link{{Rel: "self", Href: feedURL}},
+	}
+	if len(rows) > 0 {
+		feed.Updated = rows[0].CheckedAt.UTC().Format(time.RFC3339Nano)
+	} else {
+		feed.Updated = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	for _, row := range rows {
+		entry := atom.Entry{
+			Title:   fmt.Sprintf("%s@%s does not match its source repository", row.Module, row.Version),
+			ID:      fmt.Sprintf("%s#%d-%s@%s", feedURL, row.CheckedAt.UnixNano(), row.Module, row.Version),
+			Updated: row.CheckedAt.UTC().Format(time.RFC3339Nano),
+			Content: atom.Content{Type: "text", Body: fmt.Sprintf("Module: %s\nVersion: %s\n\n%s\n", row.Module, row.Version, row.Message)},
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		log.Printf("error encoding Atom feed: %s", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,202 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package modrepro verifies that module versions in the sumdb can be reproduced from their origin repository
+package modrepro
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/gosum"
+	"software.sslmate.com/src/sourcespotter/internal/origin"
+	"src.agwa.name/go-dbutil"
+)
+
+// CacheDir is the directory in which origin repositories are cloned
+var CacheDir string
+
+type reproStatus string
+
+const (
+	reproEqual   reproStatus = "equal"
+	reproUnequal reproStatus = "unequal"
+	reproFailed  reproStatus = "failed"
+)
+
+type reproResult struct {
+	Status       reproStatus
+	SourceSHA256 []byte
+	Message      sql.Null[string]
+}
+
+type originRow struct {
+	Module       string `sql:"module"`
+	Version      string `sql:"version"`
+	URL          string `sql:"url"`
+	Hash         string `sql:"hash"`
+	Subdir       string `sql:"subdir"`
+	SourceSHA256 []byte `sql:"source_sha256"`
+}
+
+// errInsecureURL is returned by syncRepo for repositories which aren't
+// served over HTTPS, which will never be cloned
+var errInsecureURL = errors.New("refusing to clone non-HTTPS repository")
+
+// ReproduceAll reproduces every module version whose origin has been
+// recorded, but which hasn't been reproduced yet.  If a repository can't be
+// synced, other than because its URL isn't HTTPS, its versions are left
+// unreproduced, so they are retried by the next call.
+func ReproduceAll(ctx context.Context) error {
+	if CacheDir == "" {
+		return errors.New("repository cache directory not configured")
+	}
+	var rows []originRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+		SELECT DISTINCT ON (o.module, o.version) o.module, o.version, o.url, o.hash, coalesce(o.subdir,'') AS subdir, r.source_sha256
+		FROM module_origin o
+		JOIN record r ON (r.module, r.version) = (o.module, o.version)
+		WHERE o.vcs = 'git' AND o.url IS NOT NULL AND o.hash IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM module_repro WHERE (module_repro.module, module_repro.version) = (o.module, o.version))
+		ORDER BY o.module, o.version, r.db_id, r.position
+	`); err != nil {
+		return fmt.Errorf("error querying unreproduced module versions: %w", err)
+	}
+
+	byURL := make(map[string][]originRow)
+	var urls []string
+	for _, row := range rows {
+		if _, ok := byURL[row.URL]; !ok {
+			urls = append(urls, row.URL)
+		}
+		byURL[row.URL] = append(byURL[row.URL], row)
+	}
+	for _, url := range urls {
+		repoDir, syncErr := syncRepo(ctx, url)
+		if syncErr != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if syncErr != nil && !errors.Is(syncErr, errInsecureURL) {
+			log.Printf("leaving %d versions from %s unreproduced for now: %s", len(byURL[url]), url, syncErr)
+			continue
+		}
+		for _, row := range byURL[url] {
+			var result *reproResult
+			if syncErr != nil {
+				result = failedResult(syncErr)
+			} else {
+				result = reproduce(repoDir, &row)
+			}
+			if result.Status == reproUnequal {
+				log.Printf("%s@%s is not reproducible from %s: %s", row.Module, row.Version, row.URL, result.Message.V)
+			}
+			if err := storeReproResult(ctx, row.Module, row.Version, result); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncRepo clones the repository at url into the cache, or fetches it if it's
+// already been cloned, and returns the path to the clone.
+func syncRepo(ctx context.Context, url string) (string, error) {
+	if !strings.HasPrefix(url, "https://") {
+		return "", fmt.Errorf("%w %q", errInsecureURL, url)
+	}
+	urlHash := sha256.Sum256([]byte(url))
+	repoDir := filepath.Join(CacheDir, hex.EncodeToString(urlHash[:]))
+
+	var cmd *exec.Cmd
+	if _, err := os.Stat(filepath.Join(repoDir, ".git")); err == nil {
+		cmd = origin.GitCommand(ctx, repoDir, "fetch", "--quiet", "--force", "--tags", "origin")
+	} else if errors.Is(err, os.ErrNotExist) {
+		if err := os.RemoveAll(repoDir); err != nil {
+			return "", err
+		}
+		cmd = origin.GitCommand(ctx, CacheDir, "clone", "--quiet", "--no-checkout", "--", url, repoDir)
+	} else {
+		return "", err
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) != 0 {
+			return "", fmt.Errorf("error syncing %s: %s", url, bytes.TrimSpace(out))
+		}
+		return "", fmt.Errorf("error executing git: %w", err)
+	}
+	return repoDir, nil
+}
+
+func reproduce(repoDir string, row *originRow) *reproResult {
+	zipHash, _, err := gosum.HashRevision(repoDir, row.Hash, row.Subdir, row.Module, row.Version)
+	if err != nil {
+		return failedResult(err)
+	}
+	sourceSHA256, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(zipHash, "h1:"))
+	if err != nil {
+		return failedResult(fmt.Errorf("invalid hash %q: %w", zipHash, err))
+	}
+	if !bytes.Equal(sourceSHA256, row.SourceSHA256) {
+		return &reproResult{
+			Status:       reproUnequal,
+			SourceSHA256: sourceSHA256,
+			Message:      sql.Null[string]{Valid: true, V: fmt.Sprintf("commit %s hashes to %s, but the checksum database contains h1:%s", row.Hash, zipHash, base64.StdEncoding.EncodeToString(row.SourceSHA256))},
+		}
+	}
+	return &reproResult{Status: reproEqual, SourceSHA256: sourceSHA256}
+}
+
+func failedResult(err error) *reproResult {
+	return &reproResult{Status: reproFailed, Message: sql.Null[string]{Valid: true, V: err.Error()}}
+}
+
+func storeReproResult(ctx context.Context, module, version string, result *reproResult) error {
+	_, err := sourcespotter.DB.ExecContext(ctx, `
+		INSERT INTO module_repro (module, version, status, source_sha256, message)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (module, version)
+		DO UPDATE SET
+			checked_at = EXCLUDED.checked_at,
+			status = EXCLUDED.status,
+			source_sha256 = EXCLUDED.source_sha256,
+			message = EXCLUDED.message
+	`, module, version, result.Status, result.SourceSHA256, result.Message)
+	if err != nil {
+		return fmt.Errorf("error storing %s reproduction result for %s@%s: %w", result.Status, module, version, err)
+	}
+	return nil
+}
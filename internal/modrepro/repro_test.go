@@ -0,0 +1,90 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modrepro
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReproduce(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repoDir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %s: %s", strings.Join(args, " "), err)
+		}
+		return string(bytes.TrimSpace(out))
+	}
+	git("init", "--quiet")
+	if err := os.MkdirAll(filepath.Join(repoDir, "sub"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "sub", "go.mod"), []byte("module example.com/repo/sub\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "sub", "sub.go"), []byte("package sub\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "initial")
+	commit := git("rev-parse", "HEAD")
+
+	row := originRow{Module: "example.com/repo/sub", Version: "v1.0.0", Hash: commit, Subdir: "sub"}
+	result := reproduce(repoDir, &row)
+	if result.Status != reproUnequal {
+		t.Fatalf("reproduce with empty source hash returned %s, want %s (message: %s)", result.Status, reproUnequal, result.Message.V)
+	}
+
+	row.SourceSHA256 = result.SourceSHA256
+	if result := reproduce(repoDir, &row); result.Status != reproEqual {
+		t.Errorf("reproduce with correct source hash returned %s, want %s (message: %s)", result.Status, reproEqual, result.Message.V)
+	}
+
+	row.Hash = strings.Repeat("0", len(commit))
+	if result := reproduce(repoDir, &row); result.Status != reproFailed {
+		t.Errorf("reproduce of nonexistent commit returned %s, want %s", result.Status, reproFailed)
+	}
+}
+
+func TestSyncRepoInsecure(t *testing.T) {
+	// Only this failure is stored permanently; others are retried
+	if _, err := syncRepo(context.Background(), "http://example.com/repo"); !errors.Is(err, errInsecureURL) {
+		t.Errorf("syncRepo of HTTP URL returned %v, want %v", err, errInsecureURL)
+	}
+}
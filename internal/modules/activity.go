@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/activity"
+	"software.sslmate.com/src/sourcespotter/internal/atom"
+)
+
+// ServeUnusualAtom serves a feed of module versions whose publication was
+// unusual, optionally restricted to a module or module prefix
+func ServeUnusualAtom(w http.ResponseWriter, req *http.Request) {
+	module := req.URL.Query().Get("module")
+
+	rows, err := activity.LoadUnusual(req.Context(), module)
+	if err != nil {
+		log.Printf("error loading unusual activity: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+
+	baseURL := "https://feeds.api." + sourcespotter.Domain + "/modules/unusual.atom"
+	feedURL := baseURL
+	title := "Unusual Module Activity"
+	if module != "" {
+		feedURL += "?" + url.Values{"module": {module}}.Encode()
+		title = fmt.Sprintf("Unusual Activity of %s", module)
+	}
+
+	feed := atom.Feed{
+		Xmlns:  "http://www.w3.org/2005/Atom",
+		ID:     feedURL,
+		Title:  title,
+		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
+		Links:  []atom.Link{{Rel: "self", Href: feedURL}},
+	}
+	if len(rows) > 0 {
+		feed.Updated = rows[0].ObservedAt.UTC().Format(time.RFC3339Nano)
+	} else {
+		feed.Updated = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	for _, r := range rows {
+		entry := atom.Entry{
+			Title:   fmt.Sprintf("Unusual publication of %s@%s", r.Module, r.Version),
+			ID:      fmt.Sprintf("%s#%s@%s", baseURL, r.Module, r.Version),
+			Updated: r.ObservedAt.UTC().Format(time.RFC3339Nano),
+			Content: atom.Content{Type: "text", Body: fmt.Sprintf("Module: %s\nVersion: %s\nObserved: %s\nScore: %d\nSignals: %s\n\n%s\n", r.Module, r.Version, r.ObservedAt.UTC().Format(time.RFC3339), r.Score, strings.Join(r.Signals, ", "), r.Detail)},
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	enc.Encode(feed)
+}
+
+// ServeRisk serves the risk annotation of a module as JSON
+func ServeRisk(w http.ResponseWriter, req *http.Request) {
+	module := req.URL.Query().Get("module")
+	if module == "" {
+		http.Error(w, "Missing module parameter", http.StatusBadRequest)
+		return
+	}
+
+	risk, err := activity.LoadRisk(req.Context(), module)
+	if err != nil {
+		log.Printf("error loading risk of %s: %s", module, err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(risk)
+}
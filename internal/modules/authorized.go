@@ -9,9 +9,13 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/authorization"
+	"software.sslmate.com/src/sourcespotter/internal/oidc"
 )
 
 const (
@@ -19,6 +23,20 @@ const (
 	hashLen    = 32
 )
 
+// LegacyCutoff is the time from which legacy (version 0) submissions are
+// rejected, since they are not bound to this instance and can be replayed.
+// If zero, they are accepted indefinitely.
+var LegacyCutoff time.Time
+
+// checkLegacyCutoff returns an error if s is a legacy submission and now is
+// at or after LegacyCutoff
+func checkLegacyCutoff(s *authorization.Submission, now time.Time) error {
+	if s.Version == 0 && !LegacyCutoff.IsZero() && !now.Before(LegacyCutoff) {
+		return fmt.Errorf("legacy submissions are no longer accepted as of %s; sign a version 1 payload instead", LegacyCutoff.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
 func parseHash(input string) ([]byte, error) {
 	if !strings.HasPrefix(input, hashPrefix) {
 		return nil, errors.New("unrecognized hash type")
@@ -34,11 +52,7 @@ func parseHash(input string) ([]byte, error) {
 }
 
 func ReceiveAuthorized(w http.ResponseWriter, req *http.Request) {
-	var body struct {
-		Ed25519   []byte
-		GoSum     string
-		Signature []byte
-	}
+	var body authorization.Submission
 	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1000000))
 	if err := dec.Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
@@ -48,8 +62,33 @@ func ReceiveAuthorized(w http.ResponseWriter, req *http.Request) {
 		http.Error(w, "Invalid JSON: trailing data", http.StatusBadRequest)
 		return
 	}
-	if !ed25519.Verify(body.Ed25519, []byte(body.GoSum), body.Signature) {
-		http.Error(w, "Permission Denied: signature validation failed", http.StatusForbidden)
+	if err := checkLegacyCutoff(&body, time.Now()); err != nil {
+		http.Error(w, "Permission Denied: "+err.Error(), http.StatusForbidden)
+		return
+	}
+	// pubkey identifies the authorizer: the submission's key, or the key
+	// which stands in for the identity of a keyless submission
+	var (
+		pubkey   []byte
+		identity *authorization.Identity
+		payload  *authorization.Payload
+		err      error
+	)
+	if body.Version == 2 {
+		payload, err = body.VerifyKeyless(sourcespotter.Domain, time.Now(), func(token, audience string) error {
+			var err error
+			identity, err = oidc.Verify(req.Context(), token, audience, time.Now())
+			return err
+		})
+		if identity != nil {
+			pubkey = identity.Key()
+		}
+	} else {
+		payload, err = body.Verify(sourcespotter.Domain, time.Now())
+		pubkey = body.Ed25519
+	}
+	if err != nil {
+		http.Error(w, "Permission Denied: "+err.Error(), http.StatusForbidden)
 		return
 	}
 
@@ -61,7 +100,31 @@ func ReceiveAuthorized(w http.ResponseWriter, req *http.Request) {
 	}
 	defer tx.Rollback()
 
-	scanner := bufio.NewScanner(strings.NewReader(body.GoSum))
+	if payload.Nonce != nil {
+		// Nonces only need to be remembered for as long as the payload's timestamp is acceptable
+		if _, err := tx.ExecContext(req.Context(), `DELETE FROM authorization_nonce WHERE received_at < $1`, time.Now().Add(-2*authorization.MaxClockSkew)); err != nil {
+			log.Print(err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return
+		}
+		result, err := tx.ExecContext(req.Context(), `INSERT INTO authorization_nonce (pubkey, nonce) VALUES ($1, $2) ON CONFLICT DO NOTHING`, pubkey, payload.Nonce)
+		if err != nil {
+			log.Print(err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			log.Print(err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return
+		} else if n == 0 {
+			http.Error(w, "Permission Denied: payload has already been submitted", http.StatusForbidden)
+			return
+		}
+	}
+
+	var modules []string
+	scanner := bufio.NewScanner(strings.NewReader(payload.GoSum))
 	lineNum := 0
 	for scanner.Scan() {
 		lineNum++
@@ -73,6 +136,13 @@ func ReceiveAuthorized(w http.ResponseWriter, req *http.Request) {
 		}
 		module := fields[0]
 		version := fields[1]
+		if identity != nil && !oidc.Covers(identity, module) {
+			http.Error(w, fmt.Sprintf("Permission Denied: %s is not permitted to authorize %s", identity, module), http.StatusForbidden)
+			return
+		}
+		if !slices.Contains(modules, module) {
+			modules = append(modules, module)
+		}
 		hash, err := parseHash(fields[2])
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Invalid hash on line %d: %v", lineNum, err), http.StatusBadRequest)
@@ -83,7 +153,7 @@ func ReceiveAuthorized(w http.ResponseWriter, req *http.Request) {
 			columnName = "gomod_sha256"
 			version = s
 		}
-		if _, err := tx.ExecContext(req.Context(), `INSERT INTO authorized_record (pubkey, module, version, `+columnName+`) VALUES ($1,$2,$3,$4) ON CONFLICT (pubkey,module,version) DO UPDATE SET `+columnName+`=excluded.`+columnName, body.Ed25519, module, version, hash); err != nil {
+		if _, err := tx.ExecContext(req.Context(), `INSERT INTO authorized_record (pubkey, module, version, `+columnName+`) VALUES ($1,$2,$3,$4) ON CONFLICT (pubkey,module,version) DO UPDATE SET `+columnName+`=excluded.`+columnName+`, authorized_at=excluded.authorized_at`, pubkey, module, version, hash); err != nil {
 			log.Print(err)
 			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
 			return
@@ -93,6 +163,11 @@ func ReceiveAuthorized(w http.ResponseWriter, req *http.Request) {
 		http.Error(w, "Invalid go.sum: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := storeSubmission(req.Context(), tx, &body, pubkey, identity, modules); err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
 	if err := tx.Commit(); err != nil {
 		log.Print(err)
 		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
@@ -101,19 +176,42 @@ func ReceiveAuthorized(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func ServeAuthorized(w http.ResponseWriter, req *http.Request) {
+// parseKeyParam parses the ed25519 or identity parameter, returning the
+// public key or the key which stands in for the identity, or nil if
+// neither is specified
+func parseKeyParam(req *http.Request) ([]byte, error) {
 	pubkeyParam := req.URL.Query().Get("ed25519")
-	if pubkeyParam == "" {
-		http.Error(w, "Missing ed25519 parameter", http.StatusBadRequest)
-		return
+	identityParam := req.URL.Query().Get("identity")
+	switch {
+	case pubkeyParam != "" && identityParam != "":
+		return nil, errors.New("The ed25519 and identity parameters cannot both be specified")
+	case pubkeyParam != "":
+		pubkey, err := base64.StdEncoding.DecodeString(pubkeyParam)
+		if err != nil {
+			return nil, errors.New("Invalid ed25519 parameter: invalid base64")
+		}
+		if len(pubkey) != ed25519.PublicKeySize {
+			return nil, errors.New("Invalid ed25519 parameter: wrong length")
+		}
+		return pubkey, nil
+	case identityParam != "":
+		identity, err := authorization.ParseIdentity(identityParam)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid identity parameter: %w", err)
+		}
+		return identity.Key(), nil
 	}
-	pubkey, err := base64.StdEncoding.DecodeString(pubkeyParam)
+	return nil, nil
+}
+
+func ServeAuthorized(w http.ResponseWriter, req *http.Request) {
+	pubkey, err := parseKeyParam(req)
 	if err != nil {
-		http.Error(w, "Invalid ed25519 parameter: invalid base64", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if len(pubkey) != ed25519.PublicKeySize {
-		http.Error(w, "Invalid ed25519 parameter: wrong length", http.StatusBadRequest)
+	if pubkey == nil {
+		http.Error(w, "Missing ed25519 or identity parameter", http.StatusBadRequest)
 		return
 	}
 
@@ -0,0 +1,59 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter/authorization"
+)
+
+func TestCheckLegacyCutoff(t *testing.T) {
+	cutoff := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	defer func(saved time.Time) { LegacyCutoff = saved }(LegacyCutoff)
+
+	tests := []struct {
+		cutoff  time.Time
+		version int
+		now     time.Time
+		wantErr bool
+	}{
+		{time.Time{}, 0, cutoff.Add(24 * time.Hour), false},
+		{cutoff, 0, cutoff.Add(-time.Second), false},
+		{cutoff, 0, cutoff, true},
+		{cutoff, 0, cutoff.Add(time.Second), true},
+		{cutoff, 1, cutoff.Add(time.Second), false},
+		{cutoff, 2, cutoff.Add(time.Second), false},
+	}
+	for _, test := range tests {
+		LegacyCutoff = test.cutoff
+		err := checkLegacyCutoff(&authorization.Submission{Version: test.version}, test.now)
+		if (err != nil) != test.wantErr {
+			t.Errorf("checkLegacyCutoff(version %d, %s) with cutoff %s returned %v", test.version, test.now, test.cutoff, err)
+		}
+	}
+}
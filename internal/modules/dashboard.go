@@ -2,17 +2,19 @@ package modules
 
 import (
 	"net/http"
+	"time"
 
 	"software.sslmate.com/src/sourcespotter"
 	basedashboard "software.sslmate.com/src/sourcespotter/internal/dashboard"
 )
 
 type dashboardData struct {
-	Domain string
+	Domain       string
+	LegacyCutoff time.Time
 }
 
 func ServeDashboard(w http.ResponseWriter, req *http.Request) {
-	data := &dashboardData{Domain: sourcespotter.Domain}
+	data := &dashboardData{Domain: sourcespotter.Domain, LegacyCutoff: LegacyCutoff}
 	basedashboard.ServePage(w, req,
 		"Go Module Monitoring - Source Spotter",
 		"Monitor the versions of your modules observed by Source Spotter.",
@@ -1,9 +1,10 @@
 package modules
 
 import (
-	"crypto/ed25519"
+	"database/sql"
 	"encoding/base64"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,11 +15,15 @@ import (
 
 	"golang.org/x/mod/semver"
 
+	"github.com/lib/pq"
 	"software.sslmate.com/src/sourcespotter"
 	"software.sslmate.com/src/sourcespotter/internal/atom"
 )
 
-const maxFeedEntries = 10_000
+const (
+	maxFeedEntries   = 10_000
+	versionsPageSize = 1000
+)
 
 type recordRow struct {
 	Module       string    `sql:"module"`
@@ -34,37 +39,65 @@ func ServeVersionsAtom(w http.ResponseWriter, req *http.Request) {
 		http.Error(w, "Missing module parameter", http.StatusBadRequest)
 		return
 	}
-	pubkeyParam := req.URL.Query().Get("ed25519")
-	var pubkey []byte
-	var err error
-	if pubkeyParam != "" {
-		pubkey, err = base64.StdEncoding.DecodeString(pubkeyParam)
-		if err != nil {
-			http.Error(w, "Invalid ed25519 parameter: invalid base64", http.StatusBadRequest)
-			return
-		}
-		if len(pubkey) != ed25519.PublicKeySize {
-			http.Error(w, "Invalid ed25519 parameter: wrong length", http.StatusBadRequest)
-			return
-		}
+	filter, ok := parseAuthorizationFilter(w, req, module)
+	if !ok {
+		return
+	}
+
+	page, err := atom.ParsePage(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
+	baseURL := "https://feeds.api." + sourcespotter.Domain + "/modules/versions.atom"
+	u, _ := url.Parse(baseURL)
+	q := u.Query()
+	q.Set("module", module)
+	filter.setParams(q)
+	u.RawQuery = q.Encode()
+	feedURL := u.String()
+
+	// Pages are formed from all of the module's records, before filtering,
+	// so that their boundaries don't move when a version is authorized.
+	// Records are ordered by the ingest batch which made them visible, so
+	// unfiltered pages never change, but filtered pages lose entries as
+	// versions are authorized (or gain them as keys are revoked).  Records
+	// ingested before batches were numbered sort first.
 	ctx := req.Context()
-	query := `SELECT module,version,source_sha256,gomod_sha256,observed_at FROM record r`
-	args := []any{}
+	moduleCondition := `module = $1`
+	moduleArg := module
 	if strings.HasSuffix(module, "/") {
-		query += ` WHERE module LIKE $1`
-		args = append(args, module+"%")
-	} else {
-		query += ` WHERE module = $1`
-		args = append(args, module)
+		moduleCondition = `module LIKE $1`
+		moduleArg = module + "%"
+	}
+	archive := atom.Archive{URL: feedURL, PageSize: versionsPageSize, Mutable: filter.cte != ""}
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT count(*) FROM record WHERE `+moduleCondition, moduleArg).Scan(&archive.Total); err != nil {
+		log.Printf("error counting records: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	start, end, ok := archive.Range(page)
+	if !ok {
+		http.Error(w, "Archive page not found", http.StatusNotFound)
+		return
+	}
+
+	args := []any{moduleArg}
+	if filter.cte != "" {
+		args = append(args, filter.arg)
 	}
-	if pubkey != nil {
-		query += ` AND NOT EXISTS (SELECT 1 FROM authorized_record ar WHERE (ar.pubkey,ar.module,ar.version,ar.source_sha256,ar.gomod_sha256) IS NOT DISTINCT FROM ($2,r.module,r.version,r.source_sha256,r.gomod_sha256))`
-		args = append(args, pubkey)
+	offsetParam, limitParam := "$"+strconv.Itoa(len(args)+1), "$"+strconv.Itoa(len(args)+2)
+	args = append(args, start, end-start)
+	query := filter.cte + `SELECT module,version,source_sha256,gomod_sha256,observed_at FROM (
+		SELECT record.*, coalesce(ingest.ingest_id, 0) AS ingest_id FROM record
+		LEFT JOIN ingest ON ingest.db_id = record.db_id AND record."position" >= ingest.start_position AND record."position" < ingest.end_position
+		WHERE ` + moduleCondition + ` ORDER BY coalesce(ingest.ingest_id, 0), record.db_id, record."position" OFFSET ` + offsetParam + ` LIMIT ` + limitParam + `
+	) r`
+	if filter.cte != "" {
+		query += ` WHERE NOT ` + filter.authorized
 	}
-	query += ` ORDER BY module, version, db_id, "position" DESC`
-	query += ` LIMIT ` + strconv.FormatInt(maxFeedEntries+1, 10)
+	query += ` ORDER BY ingest_id, db_id, "position"`
 
 	rows, err := sourcespotter.DB.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -74,30 +107,15 @@ func ServeVersionsAtom(w http.ResponseWriter, req *http.Request) {
 	}
 	defer rows.Close()
 
-	baseURL := "https://feeds.api." + sourcespotter.Domain + "/modules/versions.atom"
-	u, _ := url.Parse(baseURL)
-	q := u.Query()
-	q.Set("module", module)
-	if pubkeyParam != "" {
-		q.Set("ed25519", pubkeyParam)
-	}
-	u.RawQuery = q.Encode()
-	feedURL := u.String()
-
 	feed := atom.Feed{
 		Xmlns:  "http://www.w3.org/2005/Atom",
 		ID:     feedURL,
 		Title:  fmt.Sprintf("Versions of %s", module),
 		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
-		Link:   atom.Link{Rel: "self", Href: feedURL},
 	}
+	archive.Apply(&feed, page)
 	var latest time.Time
-	for numRows := 0; rows.Next(); numRows++ {
-		if numRows == maxFeedEntries {
-			http.Error(w, fmt.Sprintf("Sorry, there are more than %d versions matching %s and we can't create a feed that large", maxFeedEntries, module), http.StatusInternalServerError)
-			return
-		}
-
+	for rows.Next() {
 		var r recordRow
 		if err := rows.Scan(&r.Module, &r.Version, &r.SourceSHA256, &r.GomodSHA256, &r.ObservedAt); err != nil {
 			log.Printf("error scanning record: %s", err)
@@ -132,10 +150,100 @@ func ServeVersionsAtom(w http.ResponseWriter, req *http.Request) {
 
 	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Cache-Control", archive.CacheControl(page, 300))
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(http.StatusOK)
 	enc := xml.NewEncoder(w)
 	enc.Indent("", "  ")
 	enc.Encode(feed)
 }
+
+// authorizationFilter determines whether a record r is authorized, by either
+// a single key or a threshold policy
+type authorizationFilter struct {
+	pubkeyParam   string
+	identityParam string
+	policyParam   string
+	cte           string // defines valid_authorization, or empty if there is no filter
+	authorized    string // SQL expression which is true if r is authorized
+	arg           any    // value of $2 in cte and authorized
+}
+
+// parseAuthorizationFilter parses the ed25519, identity, and policy
+// parameters.  If they are invalid, it writes an error response and returns
+// false.
+func parseAuthorizationFilter(w http.ResponseWriter, req *http.Request, module string) (*authorizationFilter, bool) {
+	filter := &authorizationFilter{
+		pubkeyParam:   req.URL.Query().Get("ed25519"),
+		identityParam: req.URL.Query().Get("identity"),
+		policyParam:   req.URL.Query().Get("policy"),
+	}
+	pubkey, err := parseKeyParam(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	const matches = `(ar.module,ar.version,ar.source_sha256,ar.gomod_sha256) IS NOT DISTINCT FROM (r.module,r.version,r.source_sha256,r.gomod_sha256)`
+	switch {
+	case pubkey != nil && filter.policyParam != "":
+		http.Error(w, "The ed25519 or identity parameter and the policy parameter cannot both be specified", http.StatusBadRequest)
+		return nil, false
+	case pubkey != nil:
+		filter.cte = validAuthorizationsCTE(`SELECT $2::bytea`)
+		filter.authorized = `EXISTS (SELECT 1 FROM valid_authorization ar WHERE ` + matches + `)`
+		filter.arg = pubkey
+	case filter.policyParam != "":
+		var policyModule string
+		var policyKeys pq.ByteaArray
+		if err := sourcespotter.DB.QueryRowContext(req.Context(), `SELECT module, keys FROM authorization_policy WHERE policy_id = $1`, filter.policyParam).Scan(&policyModule, &policyKeys); errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Invalid policy parameter: no such policy", http.StatusBadRequest)
+			return nil, false
+		} else if err != nil {
+			log.Printf("error loading policy: %s", err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return nil, false
+		}
+		if !policyCovers(policyModule, module) {
+			http.Error(w, fmt.Sprintf("Policy %s only covers %s", filter.policyParam, policyModule), http.StatusBadRequest)
+			return nil, false
+		}
+		// Keys which had their own chains when the policy was submitted
+		// may have been linked by rotations since, so check again
+		if a, b, err := policySharedChain(req.Context(), policyKeys); err != nil {
+			log.Print(err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return nil, false
+		} else if a != nil {
+			http.Error(w, fmt.Sprintf("Policy %s can no longer be evaluated, because its keys %s and %s have been linked by key rotations", filter.policyParam, base64.StdEncoding.EncodeToString(a), base64.StdEncoding.EncodeToString(b)), http.StatusConflict)
+			return nil, false
+		}
+		// A version is authorized once enough distinct signers have authorized it.  Signers are counted by
+		// chain, not member, so that policy keys linked by rotations (or keys they were rotated to) count once.
+		filter.cte = validAuthorizationsCTE(`SELECT unnest(keys) FROM authorization_policy WHERE policy_id = $2`)
+		filter.authorized = `((SELECT count(DISTINCT ar.chain) FROM valid_authorization ar WHERE ` + matches + `) >= (SELECT threshold FROM authorization_policy WHERE policy_id = $2))`
+		filter.arg = filter.policyParam
+	}
+	return filter, true
+}
+
+// setParams adds the filter's parameters to q
+func (filter *authorizationFilter) setParams(q url.Values) {
+	if filter.pubkeyParam != "" {
+		q.Set("ed25519", filter.pubkeyParam)
+	}
+	if filter.identityParam != "" {
+		q.Set("identity", filter.identityParam)
+	}
+	if filter.policyParam != "" {
+		q.Set("policy", filter.policyParam)
+	}
+}
+
+// policyCovers reports whether a policy for policyModule applies to every
+// module matched by the feed's module parameter
+func policyCovers(policyModule, module string) bool {
+	if policyModule == module {
+		return true
+	}
+	return strings.HasSuffix(policyModule, "/") && (strings.HasPrefix(module, policyModule) || module+"/" == policyModule)
+}
@@ -0,0 +1,260 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/authorization"
+	"src.agwa.name/go-dbutil"
+)
+
+// validAuthorizationsCTE defines the valid_authorization table, which
+// contains the authorized records of each key returned by membersQuery and
+// every key it has been rotated to, labeled with the original key in the
+// member column.  Rotations are only followed forward: since a key can be
+// rotated at most once, and only to a key which isn't already part of a
+// rotation, each key's successors form a linear chain that nobody else can
+// join.  The chain column identifies the end of the member's chain, which is
+// shared by every member on the same chain, since they all belong to one
+// signer.  The member_chain table maps each member to its chain.
+// Authorizations made by a key at or after the time it was revoked are
+// ignored, as are rotations made by a revoked key.
+func validAuthorizationsCTE(membersQuery string) string {
+	return `
+	WITH RECURSIVE member(member) AS (
+		` + membersQuery + `
+	), valid_rotation AS (
+		SELECT kr.old_pubkey, kr.new_pubkey
+		FROM key_rotation kr
+		WHERE kr.received_at < coalesce((SELECT min(revoked_since) FROM key_revocation rv WHERE rv.pubkey = kr.old_pubkey), 'infinity')
+	), key_set(member, pubkey) AS (
+		SELECT member, member FROM member
+		UNION
+		SELECT ks.member, vr.new_pubkey
+		FROM valid_rotation vr
+		JOIN key_set ks ON ks.pubkey = vr.old_pubkey
+	), member_chain(member, chain) AS (
+		SELECT ks.member, ks.pubkey FROM key_set ks WHERE NOT EXISTS (SELECT 1 FROM valid_rotation vr WHERE vr.old_pubkey = ks.pubkey)
+	), valid_authorization AS (
+		SELECT ks.member, mc.chain, ar.*
+		FROM authorized_record ar
+		JOIN key_set ks ON ks.pubkey = ar.pubkey
+		JOIN member_chain mc ON mc.member = ks.member
+		WHERE ar.authorized_at < coalesce((SELECT min(revoked_since) FROM key_revocation rv WHERE rv.pubkey = ar.pubkey), 'infinity')
+	)
+`
+}
+
+// IsAuthorized reports whether a record has been validly authorized by
+// pubkey, or by a key it has been rotated to
+func IsAuthorized(ctx context.Context, pubkey []byte, module, version string, sourceSHA256, gomodSHA256 []byte) (bool, error) {
+	var authorized bool
+	err := sourcespotter.DB.QueryRowContext(ctx, validAuthorizationsCTE(`SELECT $1::bytea`)+`
+		SELECT EXISTS (SELECT 1 FROM valid_authorization ar WHERE (ar.module,ar.version,ar.source_sha256,ar.gomod_sha256) IS NOT DISTINCT FROM ($2,$3,$4::bytea,$5::bytea))
+	`, pubkey, module, version, sourceSHA256, gomodSHA256).Scan(&authorized)
+	return authorized, err
+}
+
+var (
+	errAlreadyRotated = errors.New("old key has already been rotated to another key")
+	errNewKeyRotated  = errors.New("new key is already part of a key rotation")
+)
+
+// checkRotation returns an error if a rotation would make a chain of
+// rotations branch (because the old key has already been rotated) or merge
+// with another chain (because the new key has already been rotated to or
+// from).  Either would let whoever holds a retired key attach a key of their
+// choosing to someone else's chain.
+func checkRotation(oldRotated, newRotated bool) error {
+	if oldRotated {
+		return errAlreadyRotated
+	}
+	if newRotated {
+		return errNewKeyRotated
+	}
+	return nil
+}
+
+// ReceiveKeyStatement stores a signed rotation or revocation statement
+func ReceiveKeyStatement(w http.ResponseWriter, req *http.Request) {
+	var body authorization.SignedStatement
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, 100000))
+	if err := dec.Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if dec.More() {
+		http.Error(w, "Invalid JSON: trailing data", http.StatusBadRequest)
+		return
+	}
+	statement, err := body.Verify(sourcespotter.Domain, time.Now())
+	if err != nil {
+		http.Error(w, "Permission Denied: "+err.Error(), http.StatusForbidden)
+		return
+	}
+
+	ctx := req.Context()
+	switch st := statement.(type) {
+	case *authorization.Rotation:
+		var revoked bool
+		if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM key_revocation WHERE pubkey = $1)`, []byte(st.Old)).Scan(&revoked); err != nil {
+			log.Print(err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			http.Error(w, "Permission Denied: old key has been revoked", http.StatusForbidden)
+			return
+		}
+		// The checks are made after storeLogged has locked the log, so
+		// concurrent rotations can't both pass them
+		if err := storeLogged(ctx, body.LogEntry, func(tx *sql.Tx, logIndex uint64, receivedAt time.Time) (bool, error) {
+			var stored, oldRotated, newRotated bool
+			if err := tx.QueryRowContext(ctx, `SELECT
+				EXISTS (SELECT 1 FROM key_rotation WHERE old_pubkey = $1 AND new_pubkey = $2),
+				EXISTS (SELECT 1 FROM key_rotation WHERE old_pubkey = $1),
+				EXISTS (SELECT 1 FROM key_rotation WHERE $2 IN (old_pubkey, new_pubkey))
+			`, []byte(st.Old), []byte(st.New)).Scan(&stored, &oldRotated, &newRotated); err != nil {
+				return false, err
+			}
+			if stored {
+				return false, nil
+			}
+			if err := checkRotation(oldRotated, newRotated); err != nil {
+				return false, err
+			}
+			return insertedRow(tx.ExecContext(ctx, `INSERT INTO key_rotation (old_pubkey, new_pubkey, statement, signature, new_signature, log_index, received_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`, []byte(st.Old), []byte(st.New), body.Statement, body.Signature, body.NewSignature, logIndex, receivedAt))
+		}); errors.Is(err, errAlreadyRotated) || errors.Is(err, errNewKeyRotated) {
+			http.Error(w, "Conflict: "+err.Error(), http.StatusConflict)
+			return
+		} else if err != nil {
+			log.Print(err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return
+		}
+	case *authorization.Revocation:
+		// A key can be revoked by itself, or by any key it has been rotated to
+		var permitted bool
+		if err := sourcespotter.DB.QueryRowContext(ctx, `
+			WITH RECURSIVE successor(pubkey) AS (
+				SELECT $1::bytea
+				UNION
+				SELECT kr.new_pubkey FROM key_rotation kr JOIN successor s ON kr.old_pubkey = s.pubkey
+			)
+			SELECT EXISTS (SELECT 1 FROM successor WHERE pubkey = $2)
+		`, []byte(st.Key), body.Ed25519).Scan(&permitted); err != nil {
+			log.Print(err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return
+		}
+		if !permitted {
+			http.Error(w, "Permission Denied: revocation must be signed by the revoked key or its successor", http.StatusForbidden)
+			return
+		}
+		if err := storeLogged(ctx, body.LogEntry, func(tx *sql.Tx, logIndex uint64, receivedAt time.Time) (bool, error) {
+			return insertedRow(tx.ExecContext(ctx, `INSERT INTO key_revocation (pubkey, revoked_since, signer, statement, signature, log_index, received_at) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING`, []byte(st.Key), st.Since, body.Ed25519, body.Statement, body.Signature, logIndex, receivedAt))
+		}); err != nil {
+			log.Print(err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// keyStatement is a signed statement, as served by ServeKeyStatements
+type keyStatement struct {
+	Ed25519      []byte    `sql:"signer"` // key which signed Statement
+	Statement    string    `sql:"statement"`
+	Signature    []byte    `sql:"signature"`
+	NewSignature []byte    `sql:"new_signature" json:",omitempty"` // rotations only
+	LogIndex     int64     `sql:"log_index"`                       // position in the authorization log
+	ReceivedAt   time.Time `sql:"received_at"`
+}
+
+// ServeKeyStatements serves, as JSON, the signed statements which mention a
+// key, or the statement at a position in the authorization log
+func ServeKeyStatements(w http.ResponseWriter, req *http.Request) {
+	pubkeyParam := req.URL.Query().Get("ed25519")
+	logIndexParam := req.URL.Query().Get("log_index")
+	if pubkeyParam == "" && logIndexParam == "" {
+		http.Error(w, "Missing ed25519 or log_index parameter", http.StatusBadRequest)
+		return
+	}
+	var pubkey []byte // nil to match any key
+	if pubkeyParam != "" {
+		var err error
+		pubkey, err = base64.StdEncoding.DecodeString(pubkeyParam)
+		if err != nil {
+			http.Error(w, "Invalid ed25519 parameter: invalid base64", http.StatusBadRequest)
+			return
+		}
+		if len(pubkey) != ed25519.PublicKeySize {
+			http.Error(w, "Invalid ed25519 parameter: wrong length", http.StatusBadRequest)
+			return
+		}
+	}
+	logIndex := int64(-1) // -1 to match any position
+	if logIndexParam != "" {
+		var err error
+		if logIndex, err = strconv.ParseInt(logIndexParam, 10, 64); err != nil || logIndex < 0 {
+			http.Error(w, "Invalid log_index parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var statements []keyStatement
+	if err := dbutil.QueryAll(req.Context(), sourcespotter.DB, &statements, `
+		SELECT old_pubkey AS signer, statement, signature, new_signature, log_index, received_at FROM key_rotation WHERE ($1::bytea IS NULL OR $1 IN (old_pubkey, new_pubkey)) AND ($2 = -1 OR log_index = $2)
+		UNION ALL
+		SELECT signer, statement, signature, NULL, log_index, received_at FROM key_revocation WHERE ($1::bytea IS NULL OR pubkey = $1) AND ($2 = -1 OR log_index = $2)
+		ORDER BY log_index
+	`, pubkey, logIndex); err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	if statements == nil {
+		statements = []keyStatement{}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(statements)
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckRotation(t *testing.T) {
+	tests := []struct {
+		oldRotated bool
+		newRotated bool
+		want       error
+	}{
+		{false, false, nil},
+		// Someone who obtains a retired key must not be able to rotate it
+		// again, to a key of their choosing
+		{true, false, errAlreadyRotated},
+		// Nor attach an existing chain to another one
+		{false, true, errNewKeyRotated},
+		{true, true, errAlreadyRotated},
+	}
+	for _, test := range tests {
+		if err := checkRotation(test.oldRotated, test.newRotated); !errors.Is(err, test.want) {
+			t.Errorf("checkRotation(%v, %v) = %v, want %v", test.oldRotated, test.newRotated, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/authorization"
+	"src.agwa.name/go-dbutil"
+)
+
+const maxPolicies = 1000
+
+// ReceivePolicy stores a threshold policy which has been signed by at
+// least its threshold number of keys
+func ReceivePolicy(w http.ResponseWriter, req *http.Request) {
+	var body authorization.SignedPolicy
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, 100000))
+	if err := dec.Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if dec.More() {
+		http.Error(w, "Invalid JSON: trailing data", http.StatusBadRequest)
+		return
+	}
+	policy, err := body.Verify(sourcespotter.Domain, time.Now())
+	if err != nil {
+		http.Error(w, "Permission Denied: "+err.Error(), http.StatusForbidden)
+		return
+	}
+
+	keys := make([][]byte, len(policy.Keys))
+	for i := range policy.Keys {
+		keys[i] = policy.Keys[i]
+	}
+	id := authorization.PolicyID(body.Policy)
+
+	if a, b, err := policySharedChain(req.Context(), keys); err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	} else if a != nil {
+		http.Error(w, fmt.Sprintf("Policy keys %s and %s are linked by key rotations, so they belong to the same signer", base64.StdEncoding.EncodeToString(a), base64.StdEncoding.EncodeToString(b)), http.StatusBadRequest)
+		return
+	}
+
+	// The signatures are stored exactly as logged, so resubmitting a stored
+	// policy with other signatures has no effect
+	ctx := req.Context()
+	if err := storeLogged(ctx, body.LogEntry, func(tx *sql.Tx, logIndex uint64, receivedAt time.Time) (bool, error) {
+		if inserted, err := insertedRow(tx.ExecContext(ctx, `INSERT INTO authorization_policy (policy_id, module, threshold, keys, policy, log_index, received_at) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING`, id, policy.Module, policy.Threshold, pq.ByteaArray(keys), body.Policy, logIndex, receivedAt)); err != nil || !inserted {
+			return false, err
+		}
+		for _, sig := range body.Signatures {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO authorization_policy_signature (policy_id, pubkey, signature) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, id, sig.Ed25519, sig.Signature); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}); err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct{ ID string }{id})
+}
+
+// memberChain is a row of the member_chain table defined by validAuthorizationsCTE
+type memberChain struct {
+	Member []byte `sql:"member"`
+	Chain  []byte `sql:"chain"`
+}
+
+// policySharedChain returns two of a policy's keys which currently belong to
+// the same chain of rotations, or nil if every key has its own chain
+func policySharedChain(ctx context.Context, keys [][]byte) ([]byte, []byte, error) {
+	var chains []memberChain
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &chains, validAuthorizationsCTE(`SELECT unnest($1::bytea[])`)+`SELECT member, chain FROM member_chain`, pq.ByteaArray(keys)); err != nil {
+		return nil, nil, fmt.Errorf("error loading chains of policy keys: %w", err)
+	}
+	a, b := sharedChain(chains)
+	return a, b, nil
+}
+
+// sharedChain returns two members which belong to the same chain of
+// rotations, or nil if every member has its own chain
+func sharedChain(chains []memberChain) ([]byte, []byte) {
+	seen := make(map[string][]byte)
+	for _, c := range chains {
+		if other, ok := seen[string(c.Chain)]; ok {
+			return other, c.Member
+		}
+		seen[string(c.Chain)] = c.Member
+	}
+	return nil, nil
+}
+
+// policyRow is a stored policy
+type policyRow struct {
+	ID         string    `sql:"policy_id"`
+	Policy     string    `sql:"policy"`
+	LogIndex   int64     `sql:"log_index"`
+	ReceivedAt time.Time `sql:"received_at"`
+}
+
+type policySignatureRow struct {
+	PolicyID  string `sql:"policy_id"`
+	Ed25519   []byte `sql:"pubkey"`
+	Signature []byte `sql:"signature"`
+}
+
+// servedPolicy is a policy, as served by ServePolicies
+type servedPolicy struct {
+	ID         string
+	Policy     string
+	Signatures []authorization.PolicySignature
+	LogIndex   int64 // position in the authorization log
+	ReceivedAt time.Time
+}
+
+// ServePolicies serves, as JSON, the policies which cover a module, or
+// the policy with the given id or position in the authorization log
+func ServePolicies(w http.ResponseWriter, req *http.Request) {
+	module := req.URL.Query().Get("module")
+	id := req.URL.Query().Get("id")
+	logIndexParam := req.URL.Query().Get("log_index")
+	if module == "" && id == "" && logIndexParam == "" {
+		http.Error(w, "Missing module, id, or log_index parameter", http.StatusBadRequest)
+		return
+	}
+	logIndex := int64(-1) // -1 to match any position
+	if logIndexParam != "" {
+		var err error
+		if logIndex, err = strconv.ParseInt(logIndexParam, 10, 64); err != nil || logIndex < 0 {
+			http.Error(w, "Invalid log_index parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := req.Context()
+	var rows []policyRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+		SELECT policy_id, policy, log_index, received_at
+		FROM authorization_policy
+		WHERE ($1 = '' OR module = $1 OR (right(module, 1) = '/' AND (starts_with($1, module) OR $1 || '/' = module)))
+		AND ($2 = '' OR policy_id = $2)
+		AND ($3 = -1 OR log_index = $3)
+		ORDER BY received_at DESC
+		LIMIT $4
+	`, module, id, logIndex, maxPolicies+1); err != nil {
+		log.Printf("error loading policies: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	if len(rows) > maxPolicies {
+		http.Error(w, fmt.Sprintf("Sorry, there are more than %d policies covering %s", maxPolicies, module), http.StatusInternalServerError)
+		return
+	}
+	ids := make([]string, len(rows))
+	for i := range rows {
+		ids[i] = rows[i].ID
+	}
+	var sigs []policySignatureRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &sigs, `SELECT policy_id, pubkey, signature FROM authorization_policy_signature WHERE policy_id = ANY($1) ORDER BY pubkey`, pq.Array(ids)); err != nil {
+		log.Printf("error loading policy signatures: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+
+	policies := make([]servedPolicy, len(rows))
+	for i, row := range rows {
+		policies[i] = servedPolicy{ID: row.ID, Policy: row.Policy, Signatures: []authorization.PolicySignature{}, LogIndex: row.LogIndex, ReceivedAt: row.ReceivedAt}
+		for _, sig := range sigs {
+			if sig.PolicyID == row.ID {
+				policies[i].Signatures = append(policies[i].Signatures, authorization.PolicySignature{Ed25519: sig.Ed25519, Signature: sig.Signature})
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(policies)
+}
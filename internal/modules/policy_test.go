@@ -0,0 +1,55 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSharedChain(t *testing.T) {
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	tests := []struct {
+		name   string
+		chains []memberChain
+		want   bool
+	}{
+		{"independent keys", []memberChain{{a, a}, {b, b}, {c, c}}, false},
+		{"rotated keys", []memberChain{{b, a}, {c, c}}, false},
+		// A single signer who rotated a to b must not be able to meet a
+		// threshold of 2 by listing both keys in a policy
+		{"single signer", []memberChain{{a, a}, {b, a}}, true},
+		{"single signer via rotation to third key", []memberChain{{a, c}, {b, b}, {c, c}}, true},
+	}
+	for _, test := range tests {
+		x, y := sharedChain(test.chains)
+		if got := x != nil; got != test.want {
+			t.Errorf("%s: sharedChain returned %q, %q", test.name, x, y)
+		} else if got && bytes.Equal(x, y) {
+			t.Errorf("%s: sharedChain returned the same member twice", test.name)
+		}
+	}
+}
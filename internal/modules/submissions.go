@@ -0,0 +1,237 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/authorization"
+	"software.sslmate.com/src/sourcespotter/internal/authlog"
+	"src.agwa.name/go-dbutil"
+)
+
+const (
+	defaultSubmissionsLimit = 100
+	maxSubmissionsLimit     = 1000
+)
+
+// storeSubmission stores a verified submission exactly as it was signed,
+// and appends it to the authorization log, so that anyone can later
+// re-verify the authorizations derived from it.  pubkey is the submission's
+// key, or for keyless submissions, identity's key.
+func storeSubmission(ctx context.Context, tx *sql.Tx, s *authorization.Submission, pubkey []byte, identity *authorization.Identity, modules []string) error {
+	if modules == nil {
+		modules = []string{}
+	}
+	var identityString, identityToken, signature any // nil for NULL
+	if identity != nil {
+		identityString, identityToken = identity.String(), s.IdentityToken
+	} else {
+		signature = s.Signature
+	}
+	receivedAt := time.Now().UTC().Truncate(time.Microsecond) // PostgreSQL's precision
+	position, err := authlog.Append(ctx, tx, s.LogEntry(receivedAt))
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO authorization_submission (submission_id, version, pubkey, signed_data, signature, identity, identity_token, modules, received_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`, position, s.Version, pubkey, s.SignedData(), signature, identityString, identityToken, pq.Array(modules), receivedAt)
+	return err
+}
+
+// storeLogged appends logEntry(receivedAt) to the authorization log and
+// calls insert to store the row it describes, with the entry's index and
+// received time.  If insert reports that the row was already stored, the
+// transaction is rolled back, so nothing is added to the log.
+func storeLogged(ctx context.Context, logEntry func(receivedAt time.Time) []byte, insert func(tx *sql.Tx, logIndex uint64, receivedAt time.Time) (bool, error)) error {
+	tx, err := sourcespotter.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	receivedAt := time.Now().UTC().Truncate(time.Microsecond) // PostgreSQL's precision
+	logIndex, err := authlog.Append(ctx, tx, logEntry(receivedAt))
+	if err != nil {
+		return err
+	}
+	if inserted, err := insert(tx, logIndex, receivedAt); err != nil {
+		return err
+	} else if !inserted {
+		return nil
+	}
+	return tx.Commit()
+}
+
+// insertedRow reports whether an INSERT ... ON CONFLICT DO NOTHING inserted a row
+func insertedRow(result sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+type submissionRow struct {
+	ID            int64     `sql:"submission_id"`
+	Version       int       `sql:"version"`
+	Ed25519       []byte    `sql:"pubkey"`
+	SignedData    string    `sql:"signed_data"`
+	Signature     []byte    `sql:"signature"`
+	Identity      string    `sql:"identity"`       // empty unless keyless
+	IdentityToken string    `sql:"identity_token"` // empty unless keyless
+	ReceivedAt    time.Time `sql:"received_at"`
+}
+
+// SubmissionRecord is an accepted authorization submission, as served by
+// ServeSubmissions.  The embedded Submission is exactly what was POSTed, so
+// it can be re-verified by calling its Verify (or VerifyKeyless) method with
+// ReceivedAt as the current time.  ID is the submission's position in the
+// authorization log, whose entry is LogEntry(ReceivedAt).
+type SubmissionRecord struct {
+	ID         int64
+	ReceivedAt time.Time
+	Identity   string `json:",omitempty"` // identity which authenticated a keyless submission
+	authorization.Submission
+}
+
+// SubmissionsPage is the JSON response of ServeSubmissions
+type SubmissionsPage struct {
+	Submissions []SubmissionRecord
+	NextCursor  string // empty if there are no more submissions
+}
+
+// ServeSubmissions serves, as paginated JSON, the accepted authorization
+// submissions, optionally only those made by a key or identity, or
+// mentioning a module,
+// in the order they were appended to the authorization log
+func ServeSubmissions(w http.ResponseWriter, req *http.Request) {
+	modulePath := req.URL.Query().Get("module")
+	pubkey, err := parseKeyParam(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := defaultSubmissionsLimit
+	if limitParam := req.URL.Query().Get("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 || limit > maxSubmissionsLimit {
+			http.Error(w, fmt.Sprintf("Invalid limit parameter: must be between 1 and %d", maxSubmissionsLimit), http.StatusBadRequest)
+			return
+		}
+	}
+	var cursor int64
+	if cursorParam := req.URL.Query().Get("cursor"); cursorParam != "" {
+		if cursor, err = strconv.ParseInt(cursorParam, 10, 64); err != nil || cursor < 0 {
+			http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rows, err := loadSubmissionRows(req.Context(), pubkey, modulePath, cursor, limit+1)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	page := SubmissionsPage{Submissions: []SubmissionRecord{}}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.NextCursor = strconv.FormatInt(rows[limit-1].ID+1, 10)
+	}
+	for i := range rows {
+		page.Submissions = append(page.Submissions, makeSubmissionRecord(&rows[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+func makeSubmissionRecord(row *submissionRow) SubmissionRecord {
+	record := SubmissionRecord{
+		ID:         row.ID,
+		ReceivedAt: row.ReceivedAt,
+		Submission: authorization.Submission{
+			Version:   row.Version,
+			Ed25519:   row.Ed25519,
+			Signature: row.Signature,
+		},
+	}
+	if row.Version == 0 {
+		record.GoSum = row.SignedData
+	} else {
+		record.Payload = row.SignedData
+	}
+	if row.Version == 2 {
+		// The key stands in for the identity, and was not part of the submission
+		record.Ed25519 = nil
+		record.Identity = row.Identity
+		record.IdentityToken = row.IdentityToken
+	}
+	return record
+}
+
+// loadSubmissionRows returns up to limit submissions, starting at ID
+// cursor, which were made by pubkey (if non-nil) and mention modulePath
+// (if non-empty)
+func loadSubmissionRows(ctx context.Context, pubkey []byte, modulePath string, cursor int64, limit int) ([]submissionRow, error) {
+	args := []any{}
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions := []string{`submission_id >= ` + arg(cursor)}
+	if pubkey != nil {
+		conditions = append(conditions, `pubkey = `+arg(pubkey))
+	}
+	if modulePath != "" {
+		conditions = append(conditions, `modules @> ARRAY[`+arg(modulePath)+`::text]`)
+	}
+
+	query := `
+		SELECT submission_id, version, pubkey, signed_data, signature, coalesce(identity, '') AS identity, coalesce(identity_token, '') AS identity_token, received_at
+		FROM authorization_submission
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY submission_id
+		LIMIT ` + strconv.Itoa(limit)
+	var rows []submissionRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter/authorization"
+	"software.sslmate.com/src/sourcespotter/internal/oidc"
+	"software.sslmate.com/src/sourcespotter/internal/oidc/oidctest"
+)
+
+func TestSubmissionRecordVerifies(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const goSum = "example.com/mod v1.0.0 h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=\n"
+	payload, err := authorization.NewPayload("example.com", goSum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := authorization.Sign(priv, payload)
+	v0 := &authorization.Submission{Ed25519: pub, GoSum: goSum, Signature: ed25519.Sign(priv, []byte(goSum))}
+
+	receivedAt := payload.Timestamp.Add(time.Minute)
+	for _, submission := range []*authorization.Submission{v1, v0} {
+		signedData := submission.Payload
+		if submission.Version == 0 {
+			signedData = submission.GoSum
+		}
+		row := submissionRow{ID: 1, Version: submission.Version, Ed25519: submission.Ed25519, SignedData: signedData, Signature: submission.Signature, ReceivedAt: receivedAt}
+		data, err := json.Marshal(makeSubmissionRecord(&row))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var record struct {
+			ReceivedAt time.Time
+			authorization.Submission
+		}
+		if err := json.Unmarshal(data, &record); err != nil {
+			t.Fatal(err)
+		}
+		got, err := record.Verify("example.com", record.ReceivedAt)
+		if err != nil {
+			t.Errorf("version %d: Verify failed: %s", submission.Version, err)
+			continue
+		}
+		if got.GoSum != goSum {
+			t.Errorf("version %d: Verify returned go.sum %q, want %q", submission.Version, got.GoSum, goSum)
+		}
+	}
+}
+
+func TestKeylessSubmissionRecordVerifies(t *testing.T) {
+	issuer, err := oidctest.NewIssuer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer issuer.Close()
+	oidc.Issuers = []oidc.Issuer{{Name: "test", URL: issuer.URL(), ModulePrefix: "example.com/{repository}"}}
+	defer func() { oidc.Issuers = nil }()
+
+	const goSum = "example.com/owner/repo v1.0.0 h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=\n"
+	payload, err := authorization.NewPayload("example.com", goSum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := string(payload.Marshal())
+	token := issuer.Token("RS256", issuer.GitHubClaims(authorization.IdentityAudience("example.com", data), "owner/repo", ".github/workflows/release.yml"))
+	identity := &authorization.Identity{Issuer: "test", Repository: "owner/repo", RepositoryID: "123", OwnerID: "456", Workflow: ".github/workflows/release.yml"}
+
+	row := submissionRow{ID: 1, Version: 2, Ed25519: identity.Key(), SignedData: data, Identity: identity.String(), IdentityToken: token, ReceivedAt: time.Now()}
+	encoded, err := json.Marshal(makeSubmissionRecord(&row))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record SubmissionRecord
+	if err := json.Unmarshal(encoded, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Ed25519 != nil {
+		t.Errorf("keyless submission record has Ed25519 %x", record.Ed25519)
+	}
+	if record.Identity != identity.String() {
+		t.Errorf("record has Identity %q, want %q", record.Identity, identity)
+	}
+
+	var verifiedIdentity *authorization.Identity
+	got, err := record.VerifyKeyless("example.com", record.ReceivedAt, func(token, audience string) error {
+		var err error
+		verifiedIdentity, err = oidc.Verify(context.Background(), token, audience, record.ReceivedAt)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("VerifyKeyless failed: %s", err)
+	}
+	if got.GoSum != goSum {
+		t.Errorf("VerifyKeyless returned go.sum %q, want %q", got.GoSum, goSum)
+	}
+	if *verifiedIdentity != *identity {
+		t.Errorf("token authenticates %s, want %s", verifiedIdentity, identity)
+	}
+	if !oidc.Covers(verifiedIdentity, "example.com/owner/repo") {
+		t.Errorf("identity does not cover its own module")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/atom"
+	"software.sslmate.com/src/sourcespotter/internal/cooldown/goproxy"
+	"software.sslmate.com/src/sourcespotter/internal/proxycheck"
+)
+
+// ServeUnrecordedAtom serves a feed of the versions which proxy.golang.org
+// lists for a watched module, but which are missing from the sumdb
+func ServeUnrecordedAtom(w http.ResponseWriter, req *http.Request) {
+	module := req.URL.Query().Get("module")
+	if module == "" {
+		http.Error(w, "Missing module parameter", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := proxycheck.LoadUnrecorded(req.Context(), goproxy.DefaultProxy, module)
+	if err != nil {
+		log.Printf("error loading unrecorded versions: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	if len(rows) > maxFeedEntries {
+		http.Error(w, fmt.Sprintf("Sorry, there are more than %d unrecorded versions matching %s and we can't create a feed that large", maxFeedEntries, module), http.StatusInternalServerError)
+		return
+	}
+
+	baseURL := "https://feeds.api." + sourcespotter.Domain + "/modules/unrecorded.atom"
+	feedURL := baseURL + "?" + url.Values{"module": {module}}.Encode()
+
+	feed := atom.Feed{
+		Xmlns:  "http://www.w3.org/2005/Atom",
+		ID:     feedURL,
+		Title:  fmt.Sprintf("Versions of %s missing from the checksum database", module),
+		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
+		Links:  []atom.Link{{Rel: "self", Href: feedURL}},
+	}
+	if len(rows) > 0 {
+		feed.Updated = rows[0].ListedAt.UTC().Format(time.RFC3339Nano)
+	} else {
+		feed.Updated = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	for _, r := range rows {
+		entry := atom.Entry{
+			Title:   fmt.Sprintf("%s@%s", r.Module, r.Version),
+			ID:      fmt.Sprintf("%s#%s@%s", baseURL, r.Module, r.Version),
+			Updated: r.ListedAt.UTC().Format(time.RFC3339Nano),
+			Content: atom.Content{Type: "text", Body: fmt.Sprintf("%s lists %s@%s, but it is not in the checksum database.\nFirst listed: %s\n", r.Proxy, r.Module, r.Version, r.ListedAt.UTC().Format(time.RFC3339))},
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	enc.Encode(feed)
+}
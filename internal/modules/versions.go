@@ -0,0 +1,295 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"software.sslmate.com/src/sourcespotter"
+	"src.agwa.name/go-dbutil"
+)
+
+const (
+	defaultVersionsLimit = 1000
+	maxVersionsLimit     = 10_000
+
+	// maxVersionsBatches bounds how many batches of records are examined
+	// for one page, so that a selective filter cannot make a request run
+	// indefinitely.  The page may then contain fewer than limit versions.
+	maxVersionsBatches = 10
+)
+
+type versionRow struct {
+	Module           string    `sql:"module"`
+	Version          string    `sql:"version"`
+	SourceSHA256     []byte    `sql:"source_sha256"`
+	GomodSHA256      []byte    `sql:"gomod_sha256"`
+	DBID             int32     `sql:"db_id"`
+	SumDB            string    `sql:"address"`
+	Position         int64     `sql:"position"`
+	PreviousPosition int64     `sql:"previous_position"` // -1 if none
+	ObservedAt       time.Time `sql:"observed_at"`
+	Authorized       bool      `sql:"authorized"`
+}
+
+// VersionRecord is a record of a module version in a checksum database, as
+// served by ServeVersions
+type VersionRecord struct {
+	Module           string
+	Version          string
+	SourceSHA256     []byte
+	GomodSHA256      []byte
+	SumDB            string
+	Position         int64
+	PreviousPosition *int64 `json:",omitempty"` // earlier position of the same version, if duplicated
+	ObservedAt       time.Time
+	Authorized       *bool `json:",omitempty"` // present if ed25519, identity, or policy is specified
+}
+
+// VersionsPage is the JSON response of ServeVersions
+type VersionsPage struct {
+	Versions   []VersionRecord
+	NextCursor string // empty if there are no more versions
+}
+
+// versionsCursor identifies the last record returned on a page
+type versionsCursor struct {
+	Module   string
+	Version  string
+	DBID     int32
+	Position int64
+}
+
+func (c *versionsCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func parseVersionsCursor(s string) (*versionsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	c := new(versionsCursor)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseTristate parses an optional boolean parameter, returning nil if it
+// is absent
+func parseTristate(req *http.Request, name string) (*bool, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: must be true or false", name)
+	}
+	return &b, nil
+}
+
+// versionFilter filters versions by their form.  Pseudo-versions are not
+// considered pre-release versions, even though semver treats most of them
+// as such.
+type versionFilter struct {
+	prerelease *bool
+	pseudo     *bool
+}
+
+func (f *versionFilter) matches(version string) bool {
+	pseudo := module.IsPseudoVersion(version)
+	prerelease := !pseudo && semver.Prerelease(version) != ""
+	if f.prerelease != nil && *f.prerelease != prerelease {
+		return false
+	}
+	if f.pseudo != nil && *f.pseudo != pseudo {
+		return false
+	}
+	return true
+}
+
+// ServeVersions serves, as paginated JSON, the records of a module, or of
+// every module with a prefix, ordered by module path, version, and position
+func ServeVersions(w http.ResponseWriter, req *http.Request) {
+	modulePath := req.URL.Query().Get("module")
+	if modulePath == "" {
+		http.Error(w, "Missing module parameter", http.StatusBadRequest)
+		return
+	}
+	var versionFilter versionFilter
+	var authorizedParam *bool
+	var err error
+	if versionFilter.prerelease, err = parseTristate(req, "prerelease"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if versionFilter.pseudo, err = parseTristate(req, "pseudo"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if authorizedParam, err = parseTristate(req, "authorized"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var since time.Time
+	if sinceParam := req.URL.Query().Get("since"); sinceParam != "" {
+		if since, err = time.Parse(time.RFC3339, sinceParam); err != nil {
+			http.Error(w, "Invalid since parameter: must be an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+	}
+	limit := defaultVersionsLimit
+	if limitParam := req.URL.Query().Get("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 || limit > maxVersionsLimit {
+			http.Error(w, fmt.Sprintf("Invalid limit parameter: must be between 1 and %d", maxVersionsLimit), http.StatusBadRequest)
+			return
+		}
+	}
+	var cursor *versionsCursor
+	if cursorParam := req.URL.Query().Get("cursor"); cursorParam != "" {
+		if cursor, err = parseVersionsCursor(cursorParam); err != nil {
+			http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	filter, ok := parseAuthorizationFilter(w, req, modulePath)
+	if !ok {
+		return
+	}
+	if authorizedParam != nil && filter.cte == "" {
+		http.Error(w, "The authorized parameter requires the ed25519, identity, or policy parameter", http.StatusBadRequest)
+		return
+	}
+
+	page := VersionsPage{Versions: []VersionRecord{}}
+	exhausted := false
+	for batch := 0; batch < maxVersionsBatches && len(page.Versions) < limit && !exhausted; batch++ {
+		rows, err := loadVersionRows(req.Context(), modulePath, filter, authorizedParam, since, cursor, limit)
+		if err != nil {
+			log.Printf("error loading versions of %s: %s", modulePath, err)
+			http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+			return
+		}
+		exhausted = len(rows) < limit
+		for i := range rows {
+			row := &rows[i]
+			cursor = &versionsCursor{Module: row.Module, Version: row.Version, DBID: row.DBID, Position: row.Position}
+			if versionFilter.matches(row.Version) {
+				page.Versions = append(page.Versions, makeVersionRecord(row, filter.cte != ""))
+				if len(page.Versions) == limit {
+					exhausted = exhausted && i == len(rows)-1
+					break
+				}
+			}
+		}
+	}
+	if !exhausted && cursor != nil {
+		page.NextCursor = cursor.encode()
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+func makeVersionRecord(row *versionRow, includeAuthorized bool) VersionRecord {
+	record := VersionRecord{
+		Module:       row.Module,
+		Version:      row.Version,
+		SourceSHA256: row.SourceSHA256,
+		GomodSHA256:  row.GomodSHA256,
+		SumDB:        row.SumDB,
+		Position:     row.Position,
+		ObservedAt:   row.ObservedAt,
+	}
+	if row.PreviousPosition >= 0 {
+		record.PreviousPosition = &row.PreviousPosition
+	}
+	if includeAuthorized {
+		record.Authorized = &row.Authorized
+	}
+	return record
+}
+
+// loadVersionRows returns up to limit records after cursor which match the
+// module path, authorization, and since filters
+func loadVersionRows(ctx context.Context, modulePath string, filter *authorizationFilter, authorized *bool, since time.Time, cursor *versionsCursor, limit int) ([]versionRow, error) {
+	args := []any{}
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	var conditions []string
+	if strings.HasSuffix(modulePath, "/") {
+		conditions = append(conditions, `r.module LIKE `+arg(modulePath+"%"))
+	} else {
+		conditions = append(conditions, `r.module = `+arg(modulePath))
+	}
+	authorizedExpr := `FALSE`
+	if filter.cte != "" {
+		arg(filter.arg) // $2, referenced by filter.cte and filter.authorized
+		authorizedExpr = filter.authorized
+		if authorized != nil {
+			conditions = append(conditions, authorizedExpr+` = `+arg(*authorized))
+		}
+	}
+	if !since.IsZero() {
+		conditions = append(conditions, `r.observed_at >= `+arg(since))
+	}
+	if cursor != nil {
+		conditions = append(conditions, `(r.module, r.version, r.db_id, r.position) > (`+arg(cursor.Module)+`, `+arg(cursor.Version)+`, `+arg(cursor.DBID)+`, `+arg(cursor.Position)+`)`)
+	}
+
+	query := filter.cte + `
+		SELECT r.module, r.version, r.source_sha256, r.gomod_sha256, r.db_id, d.address, r.position, coalesce(r.previous_position, -1) AS previous_position, r.observed_at, ` + authorizedExpr + ` AS authorized
+		FROM record r
+		JOIN db d ON d.db_id = r.db_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY r.module, r.version, r.db_id, r.position
+		LIMIT ` + strconv.Itoa(limit)
+	var rows []versionRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package modules
+
+import (
+	"testing"
+)
+
+func TestVersionFilter(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		version    string
+		prerelease *bool
+		pseudo     *bool
+		want       bool
+	}{
+		{"v1.2.3", nil, nil, true},
+		{"v1.2.3", &no, &no, true},
+		{"v1.2.3", &yes, nil, false},
+		{"v1.2.3", nil, &yes, false},
+		{"v1.2.3-rc.1", &yes, nil, true},
+		{"v1.2.3-rc.1", &no, nil, false},
+		{"v1.2.3-rc.1", nil, &no, true},
+		{"v0.0.0-20240101000000-0123456789ab", nil, &yes, true},
+		{"v0.0.0-20240101000000-0123456789ab", &no, nil, true},
+		{"v0.0.0-20240101000000-0123456789ab", &yes, nil, false},
+		{"v1.2.4-0.20240101000000-0123456789ab", &no, &yes, true},
+		{"v1.2.4-rc.1.0.20240101000000-0123456789ab", &no, &yes, true},
+		{"v2.0.0+incompatible", &no, &no, true},
+	}
+	for _, test := range tests {
+		f := versionFilter{prerelease: test.prerelease, pseudo: test.pseudo}
+		if got := f.matches(test.version); got != test.want {
+			t.Errorf("matches(%q) with prerelease=%v pseudo=%v = %v, want %v", test.version, test.prerelease != nil && *test.prerelease, test.pseudo != nil && *test.pseudo, got, test.want)
+		}
+	}
+}
+
+func TestVersionsCursor(t *testing.T) {
+	cursor := &versionsCursor{Module: "example.com/mod", Version: "v1.2.3", DBID: 1, Position: 123456}
+	parsed, err := parseVersionsCursor(cursor.encode())
+	if err != nil {
+		t.Fatalf("parseVersionsCursor returned error: %s", err)
+	}
+	if *parsed != *cursor {
+		t.Errorf("parseVersionsCursor returned %+v, want %+v", parsed, cursor)
+	}
+	if _, err := parseVersionsCursor("not a cursor!"); err == nil {
+		t.Errorf("parseVersionsCursor succeeded for invalid cursor")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter/internal/httpclient"
+)
+
+const (
+	// keySetMaxAge is how long a key set is cached
+	keySetMaxAge = time.Hour
+
+	// keySetMinAge is how long a key set is cached even if a token is
+	// signed by a key which isn't in it, to bound how often it is fetched
+	keySetMinAge = time.Minute
+)
+
+type keySet struct {
+	keys      map[string]crypto.PublicKey // by key ID
+	fetchedAt time.Time
+}
+
+var (
+	keySetsMu sync.Mutex
+	keySets   = make(map[string]*keySet) // by issuer URL
+)
+
+// lookupKey returns the issuer's public key with the given key ID,
+// fetching the issuer's key set if the cached copy is stale or doesn't
+// contain the key
+func (issuer *Issuer) lookupKey(ctx context.Context, keyID string, now time.Time) (crypto.PublicKey, error) {
+	keySetsMu.Lock()
+	set := keySets[issuer.URL]
+	keySetsMu.Unlock()
+
+	if set == nil || now.Sub(set.fetchedAt) > keySetMaxAge || (set.keys[keyID] == nil && now.Sub(set.fetchedAt) > keySetMinAge) {
+		keys, err := issuer.fetchKeySet(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching key set of %s: %w", issuer.URL, err)
+		}
+		set = &keySet{keys: keys, fetchedAt: now}
+		keySetsMu.Lock()
+		keySets[issuer.URL] = set
+		keySetsMu.Unlock()
+	}
+	key := set.keys[keyID]
+	if key == nil {
+		return nil, fmt.Errorf("token is signed by unknown key %q", keyID)
+	}
+	return key, nil
+}
+
+func (issuer *Issuer) fetchKeySet(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	jwksURL := issuer.JWKSURL
+	if jwksURL == "" {
+		data, err := httpclient.DownloadBytes(ctx, strings.TrimSuffix(issuer.URL, "/")+"/.well-known/openid-configuration")
+		if err != nil {
+			return nil, err
+		}
+		var config struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("malformed OpenID configuration: %w", err)
+		}
+		if config.JWKSURI == "" {
+			return nil, errors.New("OpenID configuration is missing jwks_uri")
+		}
+		jwksURL = config.JWKSURI
+	}
+	data, err := httpclient.DownloadBytes(ctx, jwksURL)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeySet(data)
+}
+
+// jwk is a JSON Web Key
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// parseKeySet parses a JSON Web Key Set, ignoring keys of unsupported types
+func parseKeySet(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("malformed key set: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		var key crypto.PublicKey
+		var err error
+		switch {
+		case k.KeyType == "RSA":
+			key, err = k.rsaKey()
+		case k.KeyType == "EC" && k.Curve == "P-256":
+			key, err = k.ecdsaKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.KeyID, err)
+		}
+		keys[k.KeyID] = key
+	}
+	return keys, nil
+}
+
+func (k *jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, errors.New("malformed modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("malformed exponent")
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if key.N.BitLen() < 2048 {
+		return nil, errors.New("modulus is too small")
+	}
+	return key, nil
+}
+
+func (k *jwk) ecdsaKey() (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil || len(x) != 32 {
+		return nil, errors.New("malformed x coordinate")
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil || len(y) != 32 {
+		return nil, errors.New("malformed y coordinate")
+	}
+	// crypto/ecdh rejects points which are not on the curve
+	if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
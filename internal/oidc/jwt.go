@@ -0,0 +1,157 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// audience is the aud claim, which is either a string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+type jwtClaims struct {
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	Expires   *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+}
+
+func (c *jwtClaims) check(aud string, now time.Time) error {
+	if !slices.Contains(c.Audience, aud) {
+		return fmt.Errorf("token is not intended for audience %q", aud)
+	}
+	if c.Expires == nil {
+		return errors.New("token has no expiration time")
+	}
+	if now.Add(-maxClockSkew).After(time.Unix(*c.Expires, 0)) {
+		return errors.New("token has expired")
+	}
+	if c.NotBefore != nil && now.Add(maxClockSkew).Before(time.Unix(*c.NotBefore, 0)) {
+		return errors.New("token is not yet valid")
+	}
+	return nil
+}
+
+type jwt struct {
+	header    jwtHeader
+	claims    jwtClaims
+	rawClaims map[string]any
+	signed    []byte // header and claims, as encoded in the token
+	signature []byte
+}
+
+// parseJWT parses a JSON Web Token in the compact serialization, without
+// verifying it
+func parseJWT(token string) (*jwt, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a JWT")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("token has malformed header: %w", err)
+	}
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("token has malformed claims: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("token has malformed signature: %w", err)
+	}
+	t := &jwt{
+		signed:    []byte(parts[0] + "." + parts[1]),
+		signature: signature,
+	}
+	if err := json.Unmarshal(headerJSON, &t.header); err != nil {
+		return nil, fmt.Errorf("token has malformed header: %w", err)
+	}
+	if err := json.Unmarshal(claimsJSON, &t.claims); err != nil {
+		return nil, fmt.Errorf("token has malformed claims: %w", err)
+	}
+	if err := json.Unmarshal(claimsJSON, &t.rawClaims); err != nil {
+		return nil, fmt.Errorf("token has malformed claims: %w", err)
+	}
+	return t, nil
+}
+
+// verifySignature verifies the token's signature with key, which must be
+// of the type required by the token's algorithm.  Only RS256 and ES256, the
+// algorithms used by CI providers, are supported.
+func (t *jwt) verifySignature(key crypto.PublicKey) error {
+	digest := sha256.Sum256(t.signed)
+	switch t.header.Algorithm {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("token algorithm does not match key type")
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], t.signature); err != nil {
+			return errors.New("token signature is invalid")
+		}
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve.Params().BitSize != 256 {
+			return errors.New("token algorithm does not match key type")
+		}
+		if len(t.signature) != 64 {
+			return errors.New("token signature has wrong length")
+		}
+		r := new(big.Int).SetBytes(t.signature[:32])
+		s := new(big.Int).SetBytes(t.signature[32:])
+		if !ecdsa.Verify(ecKey, digest[:], r, s) {
+			return errors.New("token signature is invalid")
+		}
+	default:
+		return fmt.Errorf("token has unsupported algorithm %q", t.header.Algorithm)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package oidc verifies OIDC identity tokens issued to CI workflows
+package oidc
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter/authorization"
+)
+
+// maxClockSkew is the leeway allowed when checking a token's validity period
+const maxClockSkew = time.Minute
+
+// Issuer is a trusted issuer of identity tokens
+type Issuer struct {
+	Name              string // identifies the issuer in identities, such as "github"
+	URL               string // value of the iss claim, such as "https://token.actions.githubusercontent.com"
+	JWKSURL           string // if empty, found using OpenID Connect discovery
+	ModulePrefix      string // modules which a repository may authorize, with {repository} replaced by the repository, such as "github.com/{repository}"
+	RepositoryClaim   string // defaults to "repository"
+	RepositoryIDClaim string // defaults to "repository_id"
+	OwnerIDClaim      string // defaults to "repository_owner_id"
+	WorkflowClaim     string // defaults to "workflow_ref"
+}
+
+// Issuers is the list of trusted issuers
+var Issuers []Issuer
+
+func findIssuer(match func(*Issuer) bool) *Issuer {
+	for i := range Issuers {
+		if match(&Issuers[i]) {
+			return &Issuers[i]
+		}
+	}
+	return nil
+}
+
+// Verify verifies token, which must be issued by one of Issuers for
+// audience and be valid at now, and returns the identity it authenticates
+func Verify(ctx context.Context, token string, audience string, now time.Time) (*authorization.Identity, error) {
+	jwt, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	issuer := findIssuer(func(i *Issuer) bool { return i.URL == jwt.claims.Issuer })
+	if issuer == nil {
+		return nil, fmt.Errorf("token issuer %q is not trusted", jwt.claims.Issuer)
+	}
+	key, err := issuer.lookupKey(ctx, jwt.header.KeyID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := jwt.verifySignature(key); err != nil {
+		return nil, err
+	}
+	if err := jwt.claims.check(audience, now); err != nil {
+		return nil, err
+	}
+
+	repository, err := stringClaim(jwt.rawClaims, cmp.Or(issuer.RepositoryClaim, "repository"))
+	if err != nil {
+		return nil, err
+	}
+	repositoryID, err := stringClaim(jwt.rawClaims, cmp.Or(issuer.RepositoryIDClaim, "repository_id"))
+	if err != nil {
+		return nil, err
+	}
+	ownerID, err := stringClaim(jwt.rawClaims, cmp.Or(issuer.OwnerIDClaim, "repository_owner_id"))
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := stringClaim(jwt.rawClaims, cmp.Or(issuer.WorkflowClaim, "workflow_ref"))
+	if err != nil {
+		return nil, err
+	}
+	// Workflow references look like owner/repo/.github/workflows/release.yml@refs/tags/v1.0.0
+	workflow, _, _ = strings.Cut(workflow, "@")
+	workflow = strings.TrimPrefix(workflow, repository+"/")
+	for _, value := range []string{repository, repositoryID, ownerID} {
+		if strings.Contains(value, ":") {
+			return nil, fmt.Errorf("claim value %q contains a colon", value)
+		}
+	}
+	return &authorization.Identity{Issuer: issuer.Name, Repository: repository, RepositoryID: repositoryID, OwnerID: ownerID, Workflow: workflow}, nil
+}
+
+// Covers reports whether identity may authorize versions of modulePath
+func Covers(identity *authorization.Identity, modulePath string) bool {
+	issuer := findIssuer(func(i *Issuer) bool { return i.Name == identity.Issuer })
+	if issuer == nil || issuer.ModulePrefix == "" {
+		return false
+	}
+	prefix := strings.ReplaceAll(issuer.ModulePrefix, "{repository}", identity.Repository)
+	return modulePath == prefix || strings.HasPrefix(modulePath, prefix+"/")
+}
+
+func stringClaim(claims map[string]any, name string) (string, error) {
+	value, ok := claims[name].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("token is missing %s claim", name)
+	}
+	return value, nil
+}
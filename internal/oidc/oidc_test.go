@@ -0,0 +1,154 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package oidc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter/authorization"
+	"software.sslmate.com/src/sourcespotter/internal/oidc/oidctest"
+)
+
+func TestVerify(t *testing.T) {
+	issuer, err := oidctest.NewIssuer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer issuer.Close()
+	Issuers = []Issuer{{Name: "test", URL: issuer.URL(), ModulePrefix: "example.com/{repository}"}}
+	defer func() { Issuers = nil }()
+
+	ctx := context.Background()
+	now := time.Now()
+	want := authorization.Identity{Issuer: "test", Repository: "owner/repo", RepositoryID: "123", OwnerID: "456", Workflow: ".github/workflows/release.yml"}
+	for _, alg := range []string{"RS256", "ES256"} {
+		token := issuer.Token(alg, issuer.GitHubClaims("aud", "owner/repo", ".github/workflows/release.yml"))
+		identity, err := Verify(ctx, token, "aud", now)
+		if err != nil {
+			t.Errorf("%s: Verify returned error: %s", alg, err)
+		} else if *identity != want {
+			t.Errorf("%s: Verify returned %+v, want %+v", alg, *identity, want)
+		}
+	}
+
+	withClaim := func(name string, value any) string {
+		claims := issuer.GitHubClaims("aud", "owner/repo", ".github/workflows/release.yml")
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return issuer.Token("RS256", claims)
+	}
+	valid := withClaim("sub", "repo:owner/repo")
+	parts := strings.Split(valid, ".")
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong audience", withClaim("aud", "other")},
+		{"audience list", withClaim("aud", []string{"a", "b"})},
+		{"expired", withClaim("exp", now.Add(-time.Hour).Unix())},
+		{"no expiration", withClaim("exp", nil)},
+		{"not yet valid", withClaim("nbf", now.Add(time.Hour).Unix())},
+		{"untrusted issuer", withClaim("iss", "https://untrusted.example")},
+		{"no repository", withClaim("repository", nil)},
+		{"no repository ID", withClaim("repository_id", nil)},
+		{"no owner ID", withClaim("repository_owner_id", nil)},
+		{"colon in owner ID", withClaim("repository_owner_id", "4:56")},
+		{"no workflow", withClaim("workflow_ref", nil)},
+		{"tampered claims", parts[0] + "." + strings.Split(withClaim("repository", "owner/other"), ".")[1] + "." + parts[2]},
+		{"unsigned", parts[0] + "." + parts[1] + "."},
+		{"alg none", "eyJhbGciOiJub25lIiwia2lkIjoicnNhIn0." + parts[1] + "." + parts[2]},
+		{"not a JWT", "token"},
+	}
+	for _, test := range tests {
+		if _, err := Verify(ctx, test.token, "aud", now); err == nil {
+			t.Errorf("%s: Verify succeeded", test.name)
+		}
+	}
+	if _, err := Verify(ctx, withClaim("aud", []string{"other", "aud"}), "aud", now); err != nil {
+		t.Errorf("Verify with audience list returned error: %s", err)
+	}
+}
+
+func TestVerifyJWKSURL(t *testing.T) {
+	issuer, err := oidctest.NewIssuer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer issuer.Close()
+	Issuers = []Issuer{{Name: "test", URL: "https://issuer.example", JWKSURL: issuer.URL() + "/jwks", RepositoryClaim: "project_path", RepositoryIDClaim: "project_id", OwnerIDClaim: "namespace_id", WorkflowClaim: "ci_config_ref_uri"}}
+	defer func() { Issuers = nil }()
+
+	claims := issuer.GitHubClaims("aud", "group/project", ".gitlab-ci.yml")
+	claims["iss"] = "https://issuer.example"
+	claims["project_path"] = "group/project"
+	claims["project_id"] = "22"
+	claims["namespace_id"] = "72"
+	claims["ci_config_ref_uri"] = "gitlab.example/group/project//.gitlab-ci.yml@refs/heads/main"
+	identity, err := Verify(context.Background(), issuer.Token("ES256", claims), "aud", time.Now())
+	if err != nil {
+		t.Fatalf("Verify returned error: %s", err)
+	}
+	want := authorization.Identity{Issuer: "test", Repository: "group/project", RepositoryID: "22", OwnerID: "72", Workflow: "gitlab.example/group/project//.gitlab-ci.yml"}
+	if *identity != want {
+		t.Errorf("Verify returned %+v, want %+v", *identity, want)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	Issuers = []Issuer{{Name: "github", ModulePrefix: "github.com/{repository}"}, {Name: "nobinding"}}
+	defer func() { Issuers = nil }()
+
+	identity := &authorization.Identity{Issuer: "github", Repository: "owner/repo", RepositoryID: "123", OwnerID: "456", Workflow: ".github/workflows/release.yml"}
+	tests := []struct {
+		module string
+		want   bool
+	}{
+		{"github.com/owner/repo", true},
+		{"github.com/owner/repo/v2", true},
+		{"github.com/owner/repo/sub/module", true},
+		{"github.com/owner/repository", false},
+		{"github.com/owner", false},
+		{"example.com/owner/repo", false},
+	}
+	for _, test := range tests {
+		if got := Covers(identity, test.module); got != test.want {
+			t.Errorf("Covers(%q) = %v, want %v", test.module, got, test.want)
+		}
+	}
+	for _, issuer := range []string{"nobinding", "unknown"} {
+		other := *identity
+		other.Issuer = issuer
+		if Covers(&other, "github.com/owner/repo") {
+			t.Errorf("Covers returned true for issuer %s", issuer)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package oidctest provides a fake OIDC issuer for tests
+package oidctest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+// Issuer is an OIDC issuer which serves OpenID Connect discovery and a key
+// set from a local HTTP server, and signs tokens with RS256 and ES256 keys
+type Issuer struct {
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+// NewIssuer starts a fake issuer.  Call Close when finished with it.
+func NewIssuer() (*Issuer, error) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	issuer := &Issuer{rsaKey: rsaKey, ecKey: ecKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, map[string]string{"issuer": issuer.URL(), "jwks_uri": issuer.URL() + "/jwks"})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		}})
+	})
+	issuer.server = httptest.NewServer(mux)
+	return issuer, nil
+}
+
+// URL returns the issuer's URL, which is the iss claim of its tokens
+func (issuer *Issuer) URL() string {
+	return issuer.server.URL
+}
+
+// Close shuts down the issuer's HTTP server
+func (issuer *Issuer) Close() {
+	issuer.server.Close()
+}
+
+// GitHubClaims returns the claims of a GitHub Actions token for repository
+// and workflow, intended for audience and valid for five minutes.  The
+// repository has ID 123 and its owner has ID 456.
+func (issuer *Issuer) GitHubClaims(audience, repository, workflow string) map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":                 issuer.URL(),
+		"aud":                 audience,
+		"iat":                 now.Unix(),
+		"nbf":                 now.Unix(),
+		"exp":                 now.Add(5 * time.Minute).Unix(),
+		"sub":                 "repo:" + repository + ":ref:refs/tags/v1.0.0",
+		"repository":          repository,
+		"repository_id":       "123",
+		"repository_owner_id": "456",
+		"workflow_ref":        repository + "/" + workflow + "@refs/tags/v1.0.0",
+	}
+}
+
+// Token returns a token containing claims, signed with alg, which is
+// either "RS256" or "ES256"
+func (issuer *Issuer) Token(alg string, claims map[string]any) string {
+	header := map[string]string{"typ": "JWT", "alg": alg}
+	switch alg {
+	case "RS256":
+		header["kid"] = "rsa"
+	case "ES256":
+		header["kid"] = "ec"
+	default:
+		panic("oidctest: unsupported algorithm " + alg)
+	}
+	signed := b64(mustMarshal(header)) + "." + b64(mustMarshal(claims))
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	if alg == "RS256" {
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, issuer.rsaKey, crypto.SHA256, digest[:])
+	} else {
+		r, s, _ := ecdsa.Sign(rand.Reader, issuer.ecKey, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + b64(signature)
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(mustMarshal(v))
+}
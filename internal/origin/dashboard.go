@@ -0,0 +1,102 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package origin
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	basedashboard "software.sslmate.com/src/sourcespotter/internal/dashboard"
+	"software.sslmate.com/src/sourcespotter/internal/proxycheck"
+	"src.agwa.name/go-dbutil"
+)
+
+type alertRow struct {
+	Module     string    `sql:"module"`
+	Version    string    `sql:"version"`
+	Type       string    `sql:"type"`
+	OldValue   string    `sql:"old_value"`
+	NewValue   string    `sql:"new_value"`
+	DetectedAt time.Time `sql:"detected_at"`
+}
+
+type errorRow struct {
+	Module    string    `sql:"module"`
+	Version   string    `sql:"version"`
+	FetchedAt time.Time `sql:"fetched_at"`
+	Error     string    `sql:"error"`
+}
+
+type dashboard struct {
+	Domain      string
+	Watchlist   []string
+	OriginCount int64
+	Alerts      []alertRow
+	Errors      []errorRow
+}
+
+func loadAlerts(ctx context.Context) ([]alertRow, error) {
+	var rows []alertRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `SELECT module, version, type, old_value, new_value, detected_at FROM origin_alert ORDER BY detected_at DESC LIMIT 1000`); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func loadDashboard(ctx context.Context) (*dashboard, error) {
+	dash := &dashboard{
+		Domain:    sourcespotter.Domain,
+		Watchlist: proxycheck.Watchlist,
+	}
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT count(*) FROM module_origin WHERE url IS NOT NULL`).Scan(&dash.OriginCount); err != nil {
+		return nil, err
+	}
+	if alerts, err := loadAlerts(ctx); err == nil {
+		dash.Alerts = alerts
+	} else {
+		return nil, err
+	}
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &dash.Errors, `SELECT module, version, fetched_at, error FROM module_origin WHERE error IS NOT NULL ORDER BY fetched_at DESC LIMIT 100`); err != nil {
+		return nil, err
+	}
+	return dash, nil
+}
+
+func ServeDashboard(w http.ResponseWriter, req *http.Request) {
+	dash, err := loadDashboard(req.Context())
+	if err != nil {
+		log.Printf("error loading origin dashboard: %s", err)
+		http.Error(w, "Internal Database Error", 500)
+		return
+	}
+	basedashboard.ServePage(w, req,
+		"Module Origins - Source Spotter",
+		"Source Spotter records the repository and commit that each module version was fetched from, and detects when they change.",
+		"origin.html", dash)
+}
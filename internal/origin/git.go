@@ -0,0 +1,128 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package origin
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"software.sslmate.com/src/sourcespotter"
+	"src.agwa.name/go-dbutil"
+)
+
+// GitCommand returns a command which runs git with a minimal environment
+// that never prompts for credentials.
+func GitCommand(ctx context.Context, dir string, arg ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", arg...)
+	cmd.Env = []string{
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"HOME=" + os.Getenv("HOME"),
+		"PATH=" + cmp.Or(os.Getenv("PATH"), "/usr/local/bin:/usr/bin/:/bin"),
+	}
+	cmd.Dir = dir
+	return cmd
+}
+
+// lsRemoteTags returns the commit that each tag in the remote repository points to
+func lsRemoteTags(ctx context.Context, repoURL string) (map[string]string, error) {
+	if !strings.HasPrefix(repoURL, "https://") {
+		return nil, fmt.Errorf("refusing to contact non-HTTPS repository %q", repoURL)
+	}
+	out, err := GitCommand(ctx, "", "ls-remote", "--tags", "--", repoURL).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) != 0 {
+			return nil, fmt.Errorf("git ls-remote %s: %s", repoURL, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("error executing git ls-remote: %w", err)
+	}
+	return parseLsRemote(out), nil
+}
+
+// parseLsRemote parses the output of git ls-remote.  For annotated tags,
+// the peeled commit (from the ^{} line) is returned instead of the tag object.
+func parseLsRemote(out []byte) map[string]string {
+	refs := make(map[string]string)
+	peeled := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		hash, ref, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		if name, isPeeled := strings.CutSuffix(ref, "^{}"); isPeeled {
+			refs[name] = hash
+			peeled[name] = true
+		} else if !peeled[ref] {
+			refs[ref] = hash
+		}
+	}
+	return refs
+}
+
+func verifyTags(ctx context.Context) error {
+	var rows []originRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `SELECT module, version, vcs, url, ref, hash FROM module_origin WHERE vcs = 'git' AND starts_with(ref, 'refs/tags/') AND url IS NOT NULL AND hash IS NOT NULL ORDER BY url`); err != nil {
+		return fmt.Errorf("error querying tagged origins: %w", err)
+	}
+	byURL := make(map[string][]originRow)
+	var urls []string
+	for _, row := range rows {
+		if _, ok := byURL[row.URL]; !ok {
+			urls = append(urls, row.URL)
+		}
+		byURL[row.URL] = append(byURL[row.URL], row)
+	}
+	for _, url := range urls {
+		refs, err := lsRemoteTags(ctx, url)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("unable to list tags of %s: %s", url, err)
+			continue
+		}
+		for _, row := range byURL[url] {
+			if current, ok := refs[row.Ref]; !ok {
+				if err := insertAlert(ctx, row.Module, row.Version, tagDeleted, row.Hash, ""); err != nil {
+					return err
+				}
+			} else if current != row.Hash {
+				if err := insertAlert(ctx, row.Module, row.Version, tagMoved, row.Hash, current); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package origin records the VCS origin of watched module versions and detects when it changes
+package origin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+
+	"golang.org/x/mod/semver"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/cooldown/goproxy"
+	"software.sslmate.com/src/sourcespotter/internal/httpclient"
+	"software.sslmate.com/src/sourcespotter/internal/proxycheck"
+	"src.agwa.name/go-dbutil"
+)
+
+type alertType string
+
+const (
+	urlChanged alertType = "url_changed"
+	tagMoved   alertType = "tag_moved"
+	tagDeleted alertType = "tag_deleted"
+)
+
+type originRow struct {
+	Module  string `sql:"module"`
+	Version string `sql:"version"`
+	VCS     string `sql:"vcs"`
+	URL     string `sql:"url"`
+	Ref     string `sql:"ref"`
+	Hash    string `sql:"hash"`
+}
+
+type urlChange struct {
+	Version string
+	OldURL  string
+	NewURL  string
+}
+
+// RefreshAll records the origin of every watched module version that doesn't
+// have one yet, and then checks for changed repository URLs and moved tags.
+// Origins which can't be fetched because of a transient failure are retried
+// by the next call.
+func RefreshAll(ctx context.Context) error {
+	modules, err := proxycheck.WatchedModules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, module := range modules {
+		if err := fetchOrigins(ctx, module); err != nil {
+			return err
+		}
+		if err := detectURLChanges(ctx, module); err != nil {
+			return err
+		}
+	}
+	return verifyTags(ctx)
+}
+
+func fetchOrigins(ctx context.Context, module string) error {
+	var versions []string
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &versions, `SELECT DISTINCT version FROM record WHERE module = $1 AND NOT EXISTS (SELECT 1 FROM module_origin WHERE (module_origin.module, module_origin.version) = (record.module, record.version))`, module); err != nil {
+		return fmt.Errorf("error querying versions of %s without origin: %w", module, err)
+	}
+	for _, version := range versions {
+		info, fetchErr := fetchInfo(ctx, module, version)
+		if fetchErr != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if httpclient.IsTransient(fetchErr) {
+			// Leave the origin unrecorded, so it's fetched again next time
+			log.Printf("leaving origin of %s@%s unrecorded for now: %s", module, version, fetchErr)
+			continue
+		}
+		if err := storeOrigin(ctx, module, version, info, fetchErr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fetchInfo(ctx context.Context, module, version string) (*goproxy.ModuleInfo, error) {
+	modulePath, err := goproxy.MakeModulePath(module)
+	if err != nil {
+		return nil, err
+	}
+	moduleVersion, err := goproxy.MakeModuleVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := httpclient.DownloadBytes(ctx, goproxy.RequestURL(goproxy.DefaultProxy, modulePath, goproxy.InfoRequest{Version: moduleVersion}))
+	if err != nil {
+		return nil, err
+	}
+	info := new(goproxy.ModuleInfo)
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("error parsing .info file: %w", err)
+	}
+	return info, nil
+}
+
+func storeOrigin(ctx context.Context, module, version string, info *goproxy.ModuleInfo, fetchErr error) error {
+	var (
+		vcs, url, ref, hash, subdir sql.Null[string]
+		errString                   sql.Null[string]
+	)
+	if fetchErr != nil {
+		errString = sql.Null[string]{V: fetchErr.Error(), Valid: true}
+	} else if o := info.Origin; o != nil {
+		vcs = sql.Null[string]{V: o.VCS, Valid: o.VCS != ""}
+		url = sql.Null[string]{V: o.URL, Valid: o.URL != ""}
+		ref = sql.Null[string]{V: o.Ref, Valid: o.Ref != ""}
+		hash = sql.Null[string]{V: o.Hash, Valid: o.Hash != ""}
+		subdir = sql.Null[string]{V: o.Subdir, Valid: o.Subdir != ""}
+	}
+	if _, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO module_origin (module, version, vcs, url, ref, hash, subdir, error) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, module, version, vcs, url, ref, hash, subdir, errString); err != nil {
+		return fmt.Errorf("error storing origin of %s@%s: %w", module, version, err)
+	}
+	return nil
+}
+
+func detectURLChanges(ctx context.Context, module string) error {
+	var rows []originRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `SELECT module, version, coalesce(vcs,'') AS vcs, url, coalesce(ref,'') AS ref, coalesce(hash,'') AS hash FROM module_origin WHERE module = $1 AND url IS NOT NULL`, module); err != nil {
+		return fmt.Errorf("error querying origins of %s: %w", module, err)
+	}
+	for _, change := range urlChanges(rows) {
+		if err := insertAlert(ctx, module, change.Version, urlChanged, change.OldURL, change.NewURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// urlChanges returns the versions whose origin URL differs from the
+// URL of the preceding version, in semver order.
+func urlChanges(rows []originRow) []urlChange {
+	rows = slices.Clone(rows)
+	slices.SortFunc(rows, func(a, b originRow) int { return semver.Compare(a.Version, b.Version) })
+	var changes []urlChange
+	for i := 1; i < len(rows); i++ {
+		if rows[i].URL != rows[i-1].URL {
+			changes = append(changes, urlChange{Version: rows[i].Version, OldURL: rows[i-1].URL, NewURL: rows[i].URL})
+		}
+	}
+	return changes
+}
+
+func insertAlert(ctx context.Context, module, version string, typ alertType, oldValue, newValue string) error {
+	result, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO origin_alert (module, version, type, old_value, new_value) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`, module, version, typ, oldValue, newValue)
+	if err != nil {
+		return fmt.Errorf("error storing origin alert for %s@%s: %w", module, version, err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("origin alert: %s@%s: %s (%q -> %q)", module, version, typ, oldValue, newValue)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package origin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestURLChanges(t *testing.T) {
+	rows := []originRow{
+		{Version: "v1.10.0", URL: "https://github.com/attacker/mod"},
+		{Version: "v1.2.0", URL: "https://github.com/example/mod"},
+		{Version: "v1.9.0", URL: "https://github.com/example/mod"},
+		{Version: "v1.1.0", URL: "https://github.com/example/mod"},
+	}
+	want := []urlChange{
+		{Version: "v1.10.0", OldURL: "https://github.com/example/mod", NewURL: "https://github.com/attacker/mod"},
+	}
+	if got := urlChanges(rows); !reflect.DeepEqual(got, want) {
+		t.Errorf("urlChanges = %v, want %v", got, want)
+	}
+	if got := urlChanges(rows[1:3]); len(got) != 0 {
+		t.Errorf("urlChanges of unchanged URLs = %v, want none", got)
+	}
+}
+
+func TestParseLsRemote(t *testing.T) {
+	out := []byte("1111111111111111111111111111111111111111\trefs/tags/v1.0.0\n" +
+		"2222222222222222222222222222222222222222\trefs/tags/v1.1.0\n" +
+		"3333333333333333333333333333333333333333\trefs/tags/v1.1.0^{}\n" +
+		"5555555555555555555555555555555555555555\trefs/tags/v1.2.0^{}\n" +
+		"4444444444444444444444444444444444444444\trefs/tags/v1.2.0\n")
+	want := map[string]string{
+		"refs/tags/v1.0.0": "1111111111111111111111111111111111111111",
+		"refs/tags/v1.1.0": "3333333333333333333333333333333333333333",
+		"refs/tags/v1.2.0": "5555555555555555555555555555555555555555",
+	}
+	if got := parseLsRemote(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLsRemote = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package ownership
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/atom"
+	"src.agwa.name/go-dbutil"
+)
+
+const (
+	maxKeys = 10_000
+
+	// reverifyInterval is how often the proofs of verified keys are checked
+	// again, since they can be removed, or the domain can change hands
+	reverifyInterval = 24 * time.Hour
+
+	// keyLifetime is how long a key stays verified after its proof last
+	// verified, if the proof can't be retrieved in the meantime
+	keyLifetime = 7 * 24 * time.Hour
+)
+
+// VerifiedKey is a key which has been proven to belong to the owner of a module path prefix
+type VerifiedKey struct {
+	Prefix     string    `sql:"prefix"`
+	Ed25519    []byte    `sql:"pubkey"`
+	Method     string    `sql:"method"`
+	Version    string    `sql:"version" json:",omitempty"`
+	VerifiedAt time.Time `sql:"verified_at"`
+	ExpiresAt  time.Time `sql:"expires_at"`
+}
+
+// LoadKeys returns unexpired verified keys.  If module is non-empty, only
+// keys whose prefix covers module are returned.  If pubkey is non-nil, only
+// that key is returned.
+func LoadKeys(ctx context.Context, module string, pubkey []byte) ([]VerifiedKey, error) {
+	var pubkeyArg any // nil for NULL
+	if pubkey != nil {
+		pubkeyArg = pubkey
+	}
+	var keys []VerifiedKey
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &keys, `
+		SELECT prefix, pubkey, method, coalesce(version,'') AS version, verified_at, expires_at
+		FROM verified_key
+		WHERE ($1 = '' OR prefix = $1 OR (right(prefix, 1) = '/' AND (starts_with($1, prefix) OR $1 || '/' = prefix)))
+		AND ($2::bytea IS NULL OR pubkey = $2)
+		AND expires_at > statement_timestamp()
+		ORDER BY verified_at DESC
+		LIMIT $3
+	`, module, pubkeyArg, maxKeys+1); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ReceiveProof verifies a proof of ownership and, if valid, records the key as verified
+func ReceiveProof(w http.ResponseWriter, req *http.Request) {
+	var proof Proof
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, 100000))
+	if err := dec.Decode(&proof); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if dec.More() {
+		http.Error(w, "Invalid JSON: trailing data", http.StatusBadRequest)
+		return
+	}
+	if err := Verify(req.Context(), DefaultResolver, &proof); errors.Is(err, ErrRateLimited) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(moduleFileInterval.Seconds())))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	} else if err != nil {
+		http.Error(w, "Permission Denied: "+err.Error(), http.StatusForbidden)
+		return
+	}
+	var version *string
+	if proof.Method == Module {
+		version = &proof.Version
+	}
+	if _, err := sourcespotter.DB.ExecContext(req.Context(), `
+		INSERT INTO verified_key (prefix, pubkey, method, version, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (prefix, pubkey)
+		DO UPDATE SET
+			method = EXCLUDED.method,
+			version = EXCLUDED.version,
+			verified_at = EXCLUDED.verified_at,
+			checked_at = EXCLUDED.checked_at,
+			expires_at = EXCLUDED.expires_at
+	`, proof.Prefix, proof.Ed25519, proof.Method, version, time.Now().Add(keyLifetime)); err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReverifyAll checks the proofs of keys which haven't been checked in the
+// last reverifyInterval.  Keys whose proofs no longer list them are deleted,
+// and keys whose proofs can't be checked are deleted once they expire.
+func ReverifyAll(ctx context.Context) error {
+	var keys []VerifiedKey
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &keys, `
+		SELECT prefix, pubkey, method, coalesce(version,'') AS version, verified_at, expires_at
+		FROM verified_key
+		WHERE checked_at < $1
+		ORDER BY checked_at
+	`, time.Now().Add(-reverifyInterval)); err != nil {
+		return fmt.Errorf("error loading keys to re-verify: %w", err)
+	}
+	for i := range keys {
+		key := &keys[i]
+		proof, err := recheck(ctx, DefaultResolver, key)
+		for errors.Is(err, ErrRateLimited) {
+			// Module downloads are rate limited, so wait and try again
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(moduleFileInterval):
+			}
+			proof, err = recheck(ctx, DefaultResolver, key)
+		}
+		if errors.Is(err, errNotListed) {
+			log.Printf("deleting verified key %x for %s: %s", key.Ed25519, key.Prefix, err)
+			// Don't delete the key if it has been verified again in the meantime
+			if _, err := sourcespotter.DB.ExecContext(ctx, `DELETE FROM verified_key WHERE prefix = $1 AND pubkey = $2 AND verified_at = $3`, key.Prefix, key.Ed25519, key.VerifiedAt); err != nil {
+				return fmt.Errorf("error deleting verified key: %w", err)
+			}
+			continue
+		} else if err != nil {
+			log.Printf("unable to re-verify key %x for %s (it expires at %s): %s", key.Ed25519, key.Prefix, key.ExpiresAt.UTC().Format(time.RFC3339), err)
+			continue
+		}
+		var version *string
+		if proof.Method == Module {
+			version = &proof.Version
+		}
+		if _, err := sourcespotter.DB.ExecContext(ctx, `
+			UPDATE verified_key SET version = $1, checked_at = statement_timestamp(), expires_at = $2
+			WHERE prefix = $3 AND pubkey = $4 AND verified_at = $5
+		`, version, time.Now().Add(keyLifetime), key.Prefix, key.Ed25519, key.VerifiedAt); err != nil {
+			return fmt.Errorf("error updating verified key: %w", err)
+		}
+	}
+	if _, err := sourcespotter.DB.ExecContext(ctx, `DELETE FROM verified_key WHERE expires_at <= statement_timestamp()`); err != nil {
+		return fmt.Errorf("error deleting expired keys: %w", err)
+	}
+	return nil
+}
+
+// recheck verifies key's proof again, returning the proof that was checked.
+// Module proofs are checked against the module's current latest version.
+func recheck(ctx context.Context, resolver Resolver, key *VerifiedKey) (*Proof, error) {
+	proof := &Proof{Prefix: key.Prefix, Ed25519: key.Ed25519, Method: Method(key.Method)}
+	if proof.Method == Module {
+		latest, err := resolver.LatestVersion(ctx, strings.TrimSuffix(key.Prefix, "/"))
+		if err != nil {
+			return nil, err
+		}
+		proof.Version = latest
+	}
+	if err := Verify(ctx, resolver, proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+func parsePubkeyParam(param string) ([]byte, error) {
+	if param == "" {
+		return nil, nil
+	}
+	pubkey, err := base64.StdEncoding.DecodeString(param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64")
+	}
+	if len(pubkey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("wrong length")
+	}
+	return pubkey, nil
+}
+
+// ServeKeys serves the verified keys as JSON
+func ServeKeys(w http.ResponseWriter, req *http.Request) {
+	module := req.URL.Query().Get("module")
+	pubkey, err := parsePubkeyParam(req.URL.Query().Get("ed25519"))
+	if err != nil {
+		http.Error(w, "Invalid ed25519 parameter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	keys, err := LoadKeys(req.Context(), module, pubkey)
+	if err != nil {
+		log.Printf("error loading verified keys: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	if len(keys) > maxKeys {
+		http.Error(w, fmt.Sprintf("Sorry, there are more than %d verified keys; try specifying a module", maxKeys), http.StatusInternalServerError)
+		return
+	}
+	if keys == nil {
+		keys = []VerifiedKey{}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keys)
+}
+
+// ServeKeysAtom serves a feed of verified keys, optionally restricted to
+// the keys whose prefix covers a module
+func ServeKeysAtom(w http.ResponseWriter, req *http.Request) {
+	module := req.URL.Query().Get("module")
+	keys, err := LoadKeys(req.Context(), module, nil)
+	if err != nil {
+		log.Printf("error loading verified keys: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+	}
+
+	baseURL := "https://feeds.api." + sourcespotter.Domain + "/modules/keys.atom"
+	feedURL := baseURL
+	title := "Verified Maintainer Keys"
+	if module != "" {
+		feedURL += "?" + url.Values{"module": {module}}.Encode()
+		title = fmt.Sprintf("Verified Maintainer Keys for %s", module)
+	}
+	feed := atom.Feed{
+		Xmlns:  "http://www.w3.org/2005/Atom",
+		ID:     feedURL,
+		Title:  title,
+		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
+		Links:  []atom.Link{{Rel: "self", Href: feedURL}},
+	}
+	if len(keys) > 0 {
+		feed.Updated = keys[0].VerifiedAt.UTC().Format(time.RFC3339Nano)
+	} else {
+		feed.Updated = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	for _, k := range keys {
+		pub64 := base64.StdEncoding.EncodeToString(k.Ed25519)
+		body := fmt.Sprintf("Prefix: %s\nEd25519: %s\nMethod: %s\n", k.Prefix, pub64, k.Method)
+		if k.Version != "" {
+			body += fmt.Sprintf("Version: %s\n", k.Version)
+		}
+		entry := atom.Entry{
+			Title:   fmt.Sprintf("Key %s verified for %s", pub64, k.Prefix),
+			ID:      fmt.Sprintf("%s#%d-%s-%s", baseURL, k.VerifiedAt.UnixNano(), k.Prefix, pub64),
+			Updated: k.VerifiedAt.UTC().Format(time.RFC3339Nano),
+			Content: atom.Content{Type: "text", Body: body},
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		log.Printf("error encoding Atom feed: %s", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,206 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package ownership
+
+import (
+	"archive/zip"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/cooldown/goproxy"
+	"software.sslmate.com/src/sourcespotter/internal/httpclient"
+	"software.sslmate.com/src/sourcespotter/internal/proxycheck"
+)
+
+const (
+	maxKeysFileSize = 64 * 1024
+
+	// moduleFileInterval is the minimum time between module downloads,
+	// since each one downloads a whole module zip
+	moduleFileInterval = 10 * time.Second
+)
+
+// ErrRateLimited is returned when too many proofs are being verified
+var ErrRateLimited = errors.New("too many proofs are being verified; try again later")
+
+// DefaultResolver retrieves proofs from the Internet, and module files from
+// proxy.golang.org, verified against the sumdb records in the database.
+// Errors are described without internal details, since they are returned
+// to the user who submitted the proof.
+var DefaultResolver Resolver = &netResolver{moduleFiles: limiter{interval: moduleFileInterval}}
+
+// wellKnownClient only connects to public addresses, since the host is
+// chosen by the user who submitted the proof
+var wellKnownClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: httpclient.CheckPublicAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+// limiter allows an operation at most once per interval
+type limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// allow reports whether the operation is allowed at now, and if so, waits
+// interval before allowing it again
+func (l *limiter) allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Before(l.next) {
+		return false
+	}
+	l.next = now.Add(l.interval)
+	return true
+}
+
+type netResolver struct {
+	moduleFiles limiter
+}
+
+func (*netResolver) WellKnown(ctx context.Context, host string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+host+wellKnownPath, nil)
+	if err != nil {
+		return nil, errors.New("invalid host")
+	}
+	resp, err := wellKnownClient.Do(req)
+	if err != nil {
+		log.Printf("error retrieving keys from %s: %s", host, err)
+		return nil, errors.New("unable to connect")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, fmt.Errorf("server returned %s: %w", resp.Status, fs.ErrNotExist)
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeysFileSize))
+	if err != nil {
+		return nil, errors.New("error reading response")
+	}
+	return data, nil
+}
+
+func (*netResolver) TXT(ctx context.Context, name string) ([]string, error) {
+	records, err := net.DefaultResolver.LookupTXT(ctx, name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, fmt.Errorf("%w: %w", err, fs.ErrNotExist)
+	}
+	return records, err
+}
+
+func (r *netResolver) ModuleFile(ctx context.Context, modulePath, version, name string) ([]byte, error) {
+	module, err := goproxy.MakeModulePath(modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module path: %w", err)
+	}
+	moduleVersion, err := goproxy.MakeModuleVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module version: %w", err)
+	}
+	var sourceSHA256 []byte
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT source_sha256 FROM record WHERE module = $1 AND version = $2 ORDER BY db_id, position LIMIT 1`, modulePath, version).Scan(&sourceSHA256); errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("module version not found in checksum database")
+	} else if err != nil {
+		log.Printf("error looking up %s@%s: %s", modulePath, version, err)
+		return nil, errors.New("internal database error")
+	}
+	if !r.moduleFiles.allow(time.Now()) {
+		return nil, ErrRateLimited
+	}
+	filename, err := proxycheck.FetchZip(ctx, goproxy.DefaultProxy, module, moduleVersion, sourceSHA256)
+	if err != nil {
+		log.Printf("error downloading %s@%s: %s", modulePath, version, err)
+		return nil, errors.New("unable to download module")
+	}
+	defer os.Remove(filename)
+	z, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+	f, err := z.Open(modulePath + "@" + version + "/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("module does not contain %s: %w", name, fs.ErrNotExist)
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, maxKeysFileSize))
+}
+
+func (*netResolver) LatestVersion(ctx context.Context, modulePath string) (string, error) {
+	var versions []string
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT array_agg(DISTINCT version) FROM record WHERE module = $1`, modulePath).Scan(pq.Array(&versions)); err != nil {
+		log.Printf("error looking up versions of %s: %s", modulePath, err)
+		return "", errors.New("internal database error")
+	}
+	latest := latestVersion(versions)
+	if latest == "" {
+		return "", errors.New("module not found in checksum database")
+	}
+	return latest, nil
+}
+
+// latestVersion returns the version which the go command resolves @latest
+// to: the highest release version, or if there are none, the highest
+// pre-release version, or if there are none, the highest pseudo-version
+func latestVersion(versions []string) string {
+	var release, prerelease, pseudo string
+	for _, v := range versions {
+		latest := &release
+		if module.IsPseudoVersion(v) {
+			latest = &pseudo
+		} else if semver.Prerelease(v) != "" {
+			latest = &prerelease
+		}
+		if semver.Compare(v, *latest) > 0 {
+			*latest = v
+		}
+	}
+	return cmp.Or(release, prerelease, pseudo)
+}
@@ -0,0 +1,185 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package ownership verifies that an authorization key belongs to the owner of a module path prefix
+package ownership
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// Method is a way of proving ownership of a module path prefix
+type Method string
+
+const (
+	WellKnown Method = "wellknown" // file at https://HOST/.well-known/sourcespotter-keys
+	DNS       Method = "dns"       // TXT record at _sourcespotter.HOST
+	Module    Method = "module"    // .sourcespotter-keys file in a published module version
+)
+
+const (
+	wellKnownPath  = "/.well-known/sourcespotter-keys"
+	dnsPrefix      = "_sourcespotter."
+	moduleKeysFile = ".sourcespotter-keys"
+)
+
+// Resolver retrieves proofs of ownership
+type Resolver interface {
+	// WellKnown returns the contents of https://host/.well-known/sourcespotter-keys
+	WellKnown(ctx context.Context, host string) ([]byte, error)
+	// TXT returns the TXT records at name
+	TXT(ctx context.Context, name string) ([]string, error)
+	// ModuleFile returns the contents of the named file in the given module
+	// version, which must be verified against the sumdb
+	ModuleFile(ctx context.Context, modulePath, version, name string) ([]byte, error)
+	// LatestVersion returns the latest version of the module in the sumdb
+	LatestVersion(ctx context.Context, modulePath string) (string, error)
+}
+
+// errNotListed is wrapped by the errors which Verify returns when a proof
+// was retrieved but doesn't list the key, or no longer exists, as opposed
+// to when it couldn't be retrieved.  Resolvers indicate that a proof
+// doesn't exist by returning an error wrapping fs.ErrNotExist.
+var errNotListed = errors.New("not listed")
+
+// Proof is a request to verify that Ed25519 belongs to the owner of Prefix
+type Proof struct {
+	Prefix  string // module path, or module path prefix ending in a slash
+	Ed25519 []byte
+	Method  Method
+	Version string // module version containing the keys file, for the Module method
+}
+
+// checkPrefix returns the host of a module path prefix, or an error if the
+// prefix is not valid.  The host must contain a dot, like the first element
+// of every module path, so that names like localhost can't be used.
+func checkPrefix(prefix string) (string, error) {
+	path := strings.TrimSuffix(prefix, "/")
+	if err := module.CheckPath(path); err != nil {
+		return "", fmt.Errorf("invalid prefix: %w", err)
+	}
+	host, _, _ := strings.Cut(path, "/")
+	if net.ParseIP(host) != nil {
+		return "", errors.New("invalid prefix: host must not be an IP address")
+	}
+	return host, nil
+}
+
+// keyListed reports whether data, which consists of lines of the form
+// "PREFIX BASE64-ED25519-KEY", lists pubkey for prefix.  Blank lines and
+// lines starting with # are ignored.
+func keyListed(data []byte, prefix string, pubkey []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if keyLineMatches(scanner.Text(), prefix, pubkey) {
+			return true
+		}
+	}
+	return false
+}
+
+func keyLineMatches(line string, prefix string, pubkey []byte) bool {
+	fields := strings.Fields(line)
+	if len(fields) != 2 || strings.HasPrefix(fields[0], "#") || fields[0] != prefix {
+		return false
+	}
+	key, err := base64.StdEncoding.DecodeString(fields[1])
+	return err == nil && bytes.Equal(key, pubkey)
+}
+
+// Verify checks the proof using resolver, returning nil if the key belongs
+// to the owner of the prefix.  Module proofs must be in the latest version
+// of the module, since earlier versions may have been published by a
+// previous owner of the module path.
+func Verify(ctx context.Context, resolver Resolver, proof *Proof) error {
+	if len(proof.Ed25519) != ed25519.PublicKeySize {
+		return errors.New("public key has wrong length")
+	}
+	host, err := checkPrefix(proof.Prefix)
+	if err != nil {
+		return err
+	}
+	switch proof.Method {
+	case WellKnown:
+		data, err := resolver.WellKnown(ctx, host)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("key for %s %w in https://%s%s, which does not exist", proof.Prefix, errNotListed, host, wellKnownPath)
+		} else if err != nil {
+			return fmt.Errorf("error retrieving https://%s%s: %w", host, wellKnownPath, err)
+		}
+		if !keyListed(data, proof.Prefix, proof.Ed25519) {
+			return fmt.Errorf("key for %s %w in https://%s%s", proof.Prefix, errNotListed, host, wellKnownPath)
+		}
+	case DNS:
+		records, err := resolver.TXT(ctx, dnsPrefix+host)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("key for %s %w in TXT records for %s, which do not exist", proof.Prefix, errNotListed, dnsPrefix+host)
+		} else if err != nil {
+			return fmt.Errorf("error looking up TXT records for %s: %w", dnsPrefix+host, err)
+		}
+		for _, record := range records {
+			if keyLineMatches(record, proof.Prefix, proof.Ed25519) {
+				return nil
+			}
+		}
+		return fmt.Errorf("key for %s %w in TXT records for %s", proof.Prefix, errNotListed, dnsPrefix+host)
+	case Module:
+		// A module can only vouch for its own path and the paths beneath it
+		modulePath := strings.TrimSuffix(proof.Prefix, "/")
+		if proof.Version == "" {
+			return errors.New("version is required for module proofs")
+		}
+		latest, err := resolver.LatestVersion(ctx, modulePath)
+		if err != nil {
+			return fmt.Errorf("error looking up latest version of %s: %w", modulePath, err)
+		}
+		if proof.Version != latest {
+			return fmt.Errorf("%s is not the latest version of %s (%s)", proof.Version, modulePath, latest)
+		}
+		data, err := resolver.ModuleFile(ctx, modulePath, proof.Version, moduleKeysFile)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("key for %s %w in %s@%s, which has no %s file", proof.Prefix, errNotListed, modulePath, proof.Version, moduleKeysFile)
+		} else if err != nil {
+			return fmt.Errorf("error retrieving %s from %s@%s: %w", moduleKeysFile, modulePath, proof.Version, err)
+		}
+		if !keyListed(data, proof.Prefix, proof.Ed25519) {
+			return fmt.Errorf("key for %s %w in %s of %s@%s", proof.Prefix, errNotListed, moduleKeysFile, modulePath, proof.Version)
+		}
+	default:
+		return fmt.Errorf("unsupported method %q", proof.Method)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package ownership
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+// localResolver is a stand-in for DefaultResolver that serves proofs from memory
+type localResolver struct {
+	wellKnown   map[string]string
+	txt         map[string][]string
+	moduleFiles map[string]string // keyed by module@version/name
+	latest      map[string]string // latest version of each module
+}
+
+func (r *localResolver) WellKnown(ctx context.Context, host string) ([]byte, error) {
+	if data, ok := r.wellKnown[host]; ok {
+		return []byte(data), nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+func (r *localResolver) TXT(ctx context.Context, name string) ([]string, error) {
+	if records, ok := r.txt[name]; ok {
+		return records, nil
+	}
+	return nil, errors.New("server misbehaving")
+}
+
+func (r *localResolver) ModuleFile(ctx context.Context, modulePath, version, name string) ([]byte, error) {
+	if data, ok := r.moduleFiles[modulePath+"@"+version+"/"+name]; ok {
+		return []byte(data), nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+func (r *localResolver) LatestVersion(ctx context.Context, modulePath string) (string, error) {
+	if version, ok := r.latest[modulePath]; ok {
+		return version, nil
+	}
+	return "", errors.New("module not found")
+}
+
+func TestVerify(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub64 := base64.StdEncoding.EncodeToString(pub)
+
+	resolver := &localResolver{
+		wellKnown: map[string]string{
+			"example.com": "# maintainer keys\nexample.com/ " + pub64 + "\n",
+		},
+		txt: map[string][]string{
+			"_sourcespotter.example.net": {"v=spf1 -all", "example.net/mod " + pub64},
+		},
+		moduleFiles: map[string]string{
+			"github.com/owner/repo@v1.0.0/.sourcespotter-keys": "github.com/owner/repo/ " + pub64 + "\n",
+			"github.com/owner/old@v1.0.0/.sourcespotter-keys":  "github.com/owner/old/ " + pub64 + "\n",
+		},
+		latest: map[string]string{
+			"github.com/owner/repo": "v1.0.0",
+			"github.com/owner/old":  "v2.0.0+incompatible",
+		},
+	}
+
+	tests := []struct {
+		proof Proof
+		ok    bool
+	}{
+		{Proof{Prefix: "example.com/", Ed25519: pub, Method: WellKnown}, true},
+		{Proof{Prefix: "example.com/", Ed25519: otherPub, Method: WellKnown}, false},
+		{Proof{Prefix: "example.com/sub/", Ed25519: pub, Method: WellKnown}, false}, // prefix not listed
+		{Proof{Prefix: "example.org/", Ed25519: pub, Method: WellKnown}, false},
+		{Proof{Prefix: "example.net/mod", Ed25519: pub, Method: DNS}, true},
+		{Proof{Prefix: "example.net/other", Ed25519: pub, Method: DNS}, false},
+		{Proof{Prefix: "github.com/owner/repo/", Ed25519: pub, Method: Module, Version: "v1.0.0"}, true},
+		{Proof{Prefix: "github.com/owner/repo/", Ed25519: pub, Method: Module}, false},
+		{Proof{Prefix: "github.com/owner/repo/", Ed25519: pub, Method: Module, Version: "v1.0.1"}, false},
+		{Proof{Prefix: "github.com/owner/old/", Ed25519: pub, Method: Module, Version: "v1.0.0"}, false}, // not the latest version
+		{Proof{Prefix: "github.com/owner/repo/", Ed25519: pub, Method: WellKnown}, false},
+		{Proof{Prefix: "127.0.0.1/mod", Ed25519: pub, Method: WellKnown}, false},
+		{Proof{Prefix: "localhost/mod", Ed25519: pub, Method: WellKnown}, false},
+		{Proof{Prefix: "metadata/", Ed25519: pub, Method: WellKnown}, false},
+		{Proof{Prefix: "example.com/", Ed25519: pub[:16], Method: WellKnown}, false},
+		{Proof{Prefix: "example.com/", Ed25519: pub, Method: "carrier-pigeon"}, false},
+	}
+	for _, test := range tests {
+		err := Verify(context.Background(), resolver, &test.proof)
+		if test.ok && err != nil {
+			t.Errorf("Verify(%s, %s) returned error: %s", test.proof.Prefix, test.proof.Method, err)
+		} else if !test.ok && err == nil {
+			t.Errorf("Verify(%s, %s) succeeded, want error", test.proof.Prefix, test.proof.Method)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := limiter{interval: 10 * time.Second}
+	tests := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, true},
+		{0, false},
+		{9 * time.Second, false},
+		{10 * time.Second, true},
+		{15 * time.Second, false},
+		{25 * time.Second, true},
+	}
+	for _, test := range tests {
+		if got := l.allow(now.Add(test.at)); got != test.want {
+			t.Errorf("allow(+%s) = %v, want %v", test.at, got, test.want)
+		}
+	}
+}
+
+func TestRecheck(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub64 := base64.StdEncoding.EncodeToString(pub)
+	resolver := &localResolver{
+		wellKnown: map[string]string{
+			"example.com": "example.com/ " + pub64 + "\n",
+			"example.org": "# no keys\n",
+		},
+		moduleFiles: map[string]string{
+			"github.com/owner/repo@v1.0.0/.sourcespotter-keys": "github.com/owner/repo/ " + pub64 + "\n",
+			"github.com/owner/repo@v1.1.0/.sourcespotter-keys": "github.com/owner/repo/ " + pub64 + "\n",
+			"github.com/owner/gone@v1.0.0/.sourcespotter-keys": "github.com/owner/gone/ " + pub64 + "\n",
+			"github.com/owner/gone@v2.0.0/go.mod":              "module github.com/owner/gone\n",
+		},
+		latest: map[string]string{
+			"github.com/owner/repo": "v1.1.0",
+			"github.com/owner/gone": "v2.0.0",
+		},
+	}
+
+	tests := []struct {
+		key         VerifiedKey
+		version     string // version checked, for module proofs
+		notListed   bool   // the proof no longer lists the key
+		unavailable bool   // the proof couldn't be checked
+	}{
+		{key: VerifiedKey{Prefix: "example.com/", Method: "wellknown"}},
+		{key: VerifiedKey{Prefix: "example.org/", Method: "wellknown"}, notListed: true},
+		{key: VerifiedKey{Prefix: "example.net/", Method: "wellknown"}, notListed: true},
+		{key: VerifiedKey{Prefix: "example.com/", Method: "dns"}, unavailable: true},
+		{key: VerifiedKey{Prefix: "github.com/owner/repo/", Method: "module", Version: "v1.0.0"}, version: "v1.1.0"},
+		{key: VerifiedKey{Prefix: "github.com/owner/gone/", Method: "module", Version: "v1.0.0"}, notListed: true},
+		{key: VerifiedKey{Prefix: "github.com/owner/missing/", Method: "module", Version: "v1.0.0"}, unavailable: true},
+	}
+	for _, test := range tests {
+		test.key.Ed25519 = pub
+		proof, err := recheck(context.Background(), resolver, &test.key)
+		switch {
+		case test.notListed:
+			if !errors.Is(err, errNotListed) {
+				t.Errorf("recheck(%s, %s) returned %v, want not listed error", test.key.Prefix, test.key.Method, err)
+			}
+		case test.unavailable:
+			if err == nil || errors.Is(err, errNotListed) {
+				t.Errorf("recheck(%s, %s) returned %v, want other error", test.key.Prefix, test.key.Method, err)
+			}
+		case err != nil:
+			t.Errorf("recheck(%s, %s) returned error: %s", test.key.Prefix, test.key.Method, err)
+		case proof.Version != test.version:
+			t.Errorf("recheck(%s, %s) checked version %q, want %q", test.key.Prefix, test.key.Method, proof.Version, test.version)
+		}
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	tests := []struct {
+		versions []string
+		want     string
+	}{
+		{nil, ""},
+		{[]string{"v1.0.0", "v1.2.0", "v1.10.0", "v2.0.0-rc.1"}, "v1.10.0"},
+		{[]string{"v1.0.0-rc.1", "v1.0.0-rc.2", "v0.0.0-20240101000000-abcdefabcdef"}, "v1.0.0-rc.2"},
+		{[]string{"v0.0.0-20240101000000-abcdefabcdef", "v0.0.0-20250101000000-abcdefabcdef"}, "v0.0.0-20250101000000-abcdefabcdef"},
+		{[]string{"v1.0.0", "v1.0.1-0.20250101000000-abcdefabcdef"}, "v1.0.0"},
+	}
+	for _, test := range tests {
+		if got := latestVersion(test.versions); got != test.want {
+			t.Errorf("latestVersion(%q) = %q, want %q", test.versions, got, test.want)
+		}
+	}
+}
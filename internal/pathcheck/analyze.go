@@ -0,0 +1,159 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package pathcheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+type findingKind string
+
+const (
+	caseCollision findingKind = "case_collision"
+	homoglyph     findingKind = "homoglyph"
+	editDistance  findingKind = "edit_distance"
+	majorVersion  findingKind = "major_version"
+)
+
+const maxEditDistance = 2
+
+type finding struct {
+	Kind    findingKind
+	Similar string
+	Detail  string
+}
+
+// homoglyphs maps character sequences to a canonical sequence that looks similar
+var homoglyphs = strings.NewReplacer(
+	"rn", "m",
+	"vv", "w",
+	"0", "o",
+	"1", "l",
+	"i", "l",
+	"_", "-",
+)
+
+// skeleton returns a form of path in which characters that look alike are
+// replaced with the same character
+func skeleton(path string) string {
+	return homoglyphs.Replace(strings.ToLower(path))
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// owner returns the first two elements of path, which for most hosts
+// identify the account that controls the module
+func owner(path string) string {
+	elems := strings.SplitN(path, "/", 3)
+	if len(elems) < 2 {
+		return path
+	}
+	return elems[0] + "/" + elems[1]
+}
+
+func related(a, b string) bool {
+	return owner(a) == owner(b) || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// comparePopular returns findings for each popular module path which looks
+// confusingly similar to path, ignoring popular modules with the same owner
+func comparePopular(path string, popular []string) []finding {
+	var findings []finding
+	pathSkeleton := skeleton(path)
+	for _, p := range popular {
+		if p == path || strings.EqualFold(p, path) || related(path, p) {
+			continue
+		}
+		if skeleton(p) == pathSkeleton {
+			findings = append(findings, finding{Kind: homoglyph, Similar: p, Detail: fmt.Sprintf("%s looks like popular module %s", path, p)})
+		} else if d := levenshtein(path, p); d <= maxEditDistance {
+			findings = append(findings, finding{Kind: editDistance, Similar: p, Detail: fmt.Sprintf("%s is within %d edits of popular module %s", path, d, p)})
+		}
+	}
+	return findings
+}
+
+// splitMajor splits a module path with a /vN suffix into its prefix and major version
+func splitMajor(path string) (string, int, bool) {
+	prefix, pathMajor, ok := module.SplitPathVersion(path)
+	if !ok || !strings.HasPrefix(pathMajor, "/v") {
+		return "", 0, false
+	}
+	major, err := strconv.Atoi(pathMajor[2:])
+	if err != nil {
+		return "", 0, false
+	}
+	return prefix, major, true
+}
+
+// checkMajor returns a finding if path has a major version suffix which skips
+// past the major versions that already exist for the same prefix, or if no
+// major versions exist for the prefix at all. existing contains the paths of
+// existing modules with the same prefix, and baseMajor is the highest major
+// version of the prefix's own versions (counting v0 as 1, and including
+// +incompatible versions), or 0 if it has none.
+func checkMajor(path string, existing []string, baseMajor int) *finding {
+	prefix, major, ok := splitMajor(path)
+	if !ok {
+		return nil
+	}
+	highest := baseMajor
+	for _, e := range existing {
+		if p, m, ok := splitMajor(e); ok && p == prefix && e != path {
+			highest = max(highest, m)
+		}
+	}
+	if highest == 0 {
+		return &finding{Kind: majorVersion, Similar: prefix, Detail: fmt.Sprintf("%s has major version %d, but %s has no versions", path, major, prefix)}
+	}
+	if major <= highest+1 {
+		return nil
+	}
+	return &finding{Kind: majorVersion, Similar: prefix, Detail: fmt.Sprintf("%s skips from major version %d to %d", path, highest, major)}
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package pathcheck
+
+import (
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"github.com/sirupsen/logrus", "github.com/siruspen/logrus", 2},
+		{"golang.org/x/net", "golang.org/x/nett", 1},
+	}
+	for _, test := range tests {
+		if got := levenshtein(test.a, test.b); got != test.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestComparePopular(t *testing.T) {
+	popular := []string{
+		"github.com/sirupsen/logrus",
+		"github.com/microsoft/go-winio",
+		"github.com/gorilla/mux",
+		"github.com/google/uuid",
+	}
+	tests := []struct {
+		path string
+		want map[string]findingKind
+	}{
+		{"github.com/siruspen/logrus", map[string]findingKind{"github.com/sirupsen/logrus": editDistance}},
+		{"github.com/rnicrosoft/go-winio", map[string]findingKind{"github.com/microsoft/go-winio": homoglyph}},
+		{"github.com/g00gle/uuid", map[string]findingKind{"github.com/google/uuid": homoglyph}},
+		{"github.com/gorilla/max", nil},                  // same owner
+		{"github.com/sirupsen/logrus/hooks/syslog", nil}, // submodule
+		{"github.com/Sirupsen/logrus", nil},              // case collisions are detected separately
+		{"github.com/example/project", nil},
+	}
+	for _, test := range tests {
+		got := make(map[string]findingKind)
+		for _, f := range comparePopular(test.path, popular) {
+			got[f.Similar] = f.Kind
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("comparePopular(%q) = %v, want %v", test.path, got, test.want)
+			continue
+		}
+		for similar, kind := range test.want {
+			if got[similar] != kind {
+				t.Errorf("comparePopular(%q) = %v, want %v", test.path, got, test.want)
+			}
+		}
+	}
+}
+
+func TestCheckMajor(t *testing.T) {
+	tests := []struct {
+		path      string
+		existing  []string
+		baseMajor int
+		want      bool
+	}{
+		{"example.com/mod/v2", nil, 1, false},
+		{"example.com/mod/v3", []string{"example.com/mod/v2"}, 1, false},
+		{"example.com/mod/v9", nil, 1, true},
+		{"example.com/mod/v5", []string{"example.com/mod/v2"}, 1, true},
+		{"example.com/mod/v7", nil, 0, true},
+		{"example.com/mod/v2", nil, 0, true},
+		{"example.com/mod/v3", nil, 2, false}, // after v2.x.x+incompatible
+		{"example.com/mod/v2", []string{"example.com/mod/v3"}, 0, false},
+		{"example.com/mod", []string{"example.com/mod/v2"}, 0, false},
+		{"gopkg.in/yaml.v9", []string{"gopkg.in/yaml.v2"}, 0, false},
+	}
+	for _, test := range tests {
+		if got := checkMajor(test.path, test.existing, test.baseMajor) != nil; got != test.want {
+			t.Errorf("checkMajor(%q, %v, %d) = %v, want %v", test.path, test.existing, test.baseMajor, got, test.want)
+		}
+	}
+}
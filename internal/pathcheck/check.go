@@ -0,0 +1,171 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package pathcheck flags new module paths which may be confused with existing ones
+package pathcheck
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"software.sslmate.com/src/sourcespotter"
+	"src.agwa.name/go-dbutil"
+)
+
+const (
+	scanBatchSize = 10000
+	popularCount  = 1000
+)
+
+// Allowlist contains module paths which are never flagged.  Entries ending
+// in a slash match every module with that prefix.
+var Allowlist []string
+
+type recordRow struct {
+	DBID     int32  `sql:"db_id"`
+	Position int64  `sql:"position"`
+	Module   string `sql:"module"`
+}
+
+func allowed(module string) bool {
+	for _, entry := range Allowlist {
+		if module == entry || (strings.HasSuffix(entry, "/") && strings.HasPrefix(module, entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAll checks every module path which has appeared in the sumdb since
+// the last check.  The first time it runs for a sumdb, it starts from the
+// sumdb's current position and does not check existing modules.
+func CheckAll(ctx context.Context) error {
+	var dbIDs []int32
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &dbIDs, `SELECT db_id FROM db ORDER BY db_id`); err != nil {
+		return fmt.Errorf("error querying sumdbs: %w", err)
+	}
+	popular, err := loadPopular(ctx)
+	if err != nil {
+		return err
+	}
+	for _, dbID := range dbIDs {
+		if err := checkDB(ctx, dbID, popular); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPopular returns the modules with the most dependents
+func loadPopular(ctx context.Context) ([]string, error) {
+	var popular []string
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &popular, `SELECT dep_module FROM gomod_require GROUP BY dep_module ORDER BY count(DISTINCT module) DESC LIMIT $1`, popularCount); err != nil {
+		return nil, fmt.Errorf("error querying popular modules: %w", err)
+	}
+	return popular, nil
+}
+
+func checkDB(ctx context.Context, dbID int32, popular []string) error {
+	if _, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO module_path_scan (db_id, position) SELECT $1, coalesce(max(position), -1) FROM record WHERE db_id = $1 ON CONFLICT DO NOTHING`, dbID); err != nil {
+		return fmt.Errorf("error initializing module path scan: %w", err)
+	}
+	for {
+		var rows []recordRow
+		if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+			SELECT r.db_id, r.position, r.module
+			FROM record r
+			JOIN module_path_scan s ON s.db_id = r.db_id
+			WHERE r.db_id = $1 AND r.position > s.position
+			ORDER BY r.position
+			LIMIT $2
+		`, dbID, scanBatchSize); err != nil {
+			return fmt.Errorf("error querying new records: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for _, row := range rows {
+			if err := checkRecord(ctx, &row, popular); err != nil {
+				return err
+			}
+		}
+		if _, err := sourcespotter.DB.ExecContext(ctx, `UPDATE module_path_scan SET position = $2 WHERE db_id = $1`, dbID, rows[len(rows)-1].Position); err != nil {
+			return fmt.Errorf("error updating module path scan position: %w", err)
+		}
+	}
+}
+
+func checkRecord(ctx context.Context, row *recordRow, popular []string) error {
+	var firstSeen bool
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT NOT EXISTS (SELECT 1 FROM record WHERE module = $1 AND (db_id <> $2 OR position < $3))`, row.Module, row.DBID, row.Position).Scan(&firstSeen); err != nil {
+		return fmt.Errorf("error checking if %s is new: %w", row.Module, err)
+	}
+	if !firstSeen || allowed(row.Module) {
+		return nil
+	}
+
+	var findings []finding
+
+	var collisions []string
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &collisions, `SELECT DISTINCT module FROM record WHERE lower(module) = lower($1) AND module <> $1`, row.Module); err != nil {
+		return fmt.Errorf("error querying case-fold collisions of %s: %w", row.Module, err)
+	}
+	for _, c := range collisions {
+		findings = append(findings, finding{Kind: caseCollision, Similar: c, Detail: fmt.Sprintf("%s differs from %s only in case", row.Module, c)})
+	}
+
+	findings = append(findings, comparePopular(row.Module, popular)...)
+
+	if prefix, _, ok := splitMajor(row.Module); ok {
+		var existing []string
+		if err := dbutil.QueryAll(ctx, sourcespotter.DB, &existing, `SELECT DISTINCT module FROM record WHERE module >= $1 || '/v' AND module < $1 || '/w'`, prefix); err != nil {
+			return fmt.Errorf("error querying major versions of %s: %w", prefix, err)
+		}
+		var baseMajor int
+		if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT coalesce(max(greatest(substring(version FROM '^v([0-9]+)\.')::int, 1)), 0) FROM record WHERE module = $1`, prefix).Scan(&baseMajor); err != nil {
+			return fmt.Errorf("error querying versions of %s: %w", prefix, err)
+		}
+		if f := checkMajor(row.Module, existing, baseMajor); f != nil {
+			findings = append(findings, *f)
+		}
+	}
+
+	for _, f := range findings {
+		if err := storeFinding(ctx, row.Module, &f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func storeFinding(ctx context.Context, module string, f *finding) error {
+	if _, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO module_path_alert (module, kind, similar_module, detail) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`, module, f.Kind, f.Similar, f.Detail); err != nil {
+		return fmt.Errorf("error storing module path alert for %s: %w", module, err)
+	}
+	log.Printf("module path alert: %s", f.Detail)
+	return nil
+}
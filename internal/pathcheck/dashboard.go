@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package pathcheck
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	basedashboard "software.sslmate.com/src/sourcespotter/internal/dashboard"
+	"src.agwa.name/go-dbutil"
+)
+
+type alertRow struct {
+	Module        string    `sql:"module"`
+	Kind          string    `sql:"kind"`
+	SimilarModule string    `sql:"similar_module"`
+	Detail        string    `sql:"detail"`
+	DetectedAt    time.Time `sql:"detected_at"`
+}
+
+type dashboard struct {
+	Domain string
+	Alerts []alertRow
+}
+
+// loadAlerts returns the most recent alerts, omitting modules which have
+// been added to the Allowlist since they were flagged
+func loadAlerts(ctx context.Context) ([]alertRow, error) {
+	var rows []alertRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `SELECT module, kind, similar_module, detail, detected_at FROM module_path_alert ORDER BY detected_at DESC LIMIT 1000`); err != nil {
+		return nil, err
+	}
+	alerts := rows[:0]
+	for _, row := range rows {
+		if !allowed(row.Module) {
+			alerts = append(alerts, row)
+		}
+	}
+	return alerts, nil
+}
+
+func ServeDashboard(w http.ResponseWriter, req *http.Request) {
+	alerts, err := loadAlerts(req.Context())
+	if err != nil {
+		log.Printf("error loading pathcheck dashboard: %s", err)
+		http.Error(w, "Internal Database Error", 500)
+		return
+	}
+	basedashboard.ServePage(w, req,
+		"Module Path Confusion - Source Spotter",
+		"Source Spotter flags new module paths which could be confused with existing modules.",
+		"pathcheck.html", &dashboard{Domain: sourcespotter.Domain, Alerts: alerts})
+}
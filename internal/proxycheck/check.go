@@ -0,0 +1,211 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package proxycheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/sync/errgroup"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/cooldown/goproxy"
+	"software.sslmate.com/src/sourcespotter/internal/httpclient"
+	"src.agwa.name/go-dbutil"
+)
+
+const (
+	checkBatchSize   = 100
+	checkConcurrency = 10
+
+	// A version whose check failed, other than because of a transient
+	// download failure (which isn't stored), is checked again after this long
+	failedRecheckInterval = 24 * time.Hour
+)
+
+var (
+	// Watchlist contains module paths whose every version is checked.
+	// Entries ending in a slash match every module with that prefix.
+	Watchlist []string
+
+	// If SampleInterval is non-zero, one in every SampleInterval
+	// records is also checked.
+	SampleInterval int64
+)
+
+type checkStatus string
+
+const (
+	checkEqual   checkStatus = "equal"
+	checkUnequal checkStatus = "unequal"
+	checkFailed  checkStatus = "failed"
+)
+
+type checkResult struct {
+	Status  checkStatus
+	Message sql.Null[string]
+}
+
+type recordRow struct {
+	Module       string `sql:"module"`
+	Version      string `sql:"version"`
+	SourceSHA256 []byte `sql:"source_sha256"`
+	GomodSHA256  []byte `sql:"gomod_sha256"`
+}
+
+func splitWatchlist(watchlist []string) (modules []string, prefixes []string) {
+	for _, entry := range watchlist {
+		if strings.HasSuffix(entry, "/") {
+			prefixes = append(prefixes, entry)
+		} else {
+			modules = append(modules, entry)
+		}
+	}
+	return modules, prefixes
+}
+
+// CheckAll verifies the go.mod and zip files served by proxy.golang.org for
+// every watched or sampled record which hasn't been checked yet, and then
+// polls every proxy for the versions of watched modules
+func CheckAll(ctx context.Context) error {
+	if err := checkDefaultProxy(ctx); err != nil {
+		return err
+	}
+	return PollAll(ctx)
+}
+
+func checkDefaultProxy(ctx context.Context) error {
+	if len(Watchlist) == 0 && SampleInterval <= 0 {
+		return nil
+	}
+	modules, prefixes := splitWatchlist(Watchlist)
+	recheckBefore := time.Now().Add(-failedRecheckInterval)
+	var after recordRow // continue after this module version, which may have been left unchecked
+	for {
+		var rows []recordRow
+		if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+			SELECT DISTINCT ON (module, version) module, version, source_sha256, gomod_sha256
+			FROM record
+			WHERE (module = ANY($1) OR EXISTS (SELECT 1 FROM unnest($2::text[]) AS prefix WHERE starts_with(module, prefix)) OR ($3::bigint > 0 AND position % $3::bigint = 0))
+			AND (module, version) > ($6, $7)
+			AND NOT EXISTS (SELECT 1 FROM proxy_check WHERE (proxy_check.proxy, proxy_check.module, proxy_check.version) = ($4, record.module, record.version) AND (proxy_check.status <> 'failed' OR proxy_check.checked_at >= $8))
+			ORDER BY module, version, db_id, position
+			LIMIT $5
+		`, pq.Array(modules), pq.Array(prefixes), SampleInterval, goproxy.DefaultProxy, checkBatchSize, after.Module, after.Version, recheckBefore); err != nil {
+			return fmt.Errorf("error querying unchecked records: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		after = rows[len(rows)-1]
+		if err := checkRecords(ctx, goproxy.DefaultProxy, rows); err != nil {
+			return err
+		}
+	}
+}
+
+func checkRecords(ctx context.Context, proxyURL string, rows []recordRow) error {
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(checkConcurrency)
+	for _, r := range rows {
+		group.Go(func() error {
+			return check(groupCtx, proxyURL, &r)
+		})
+	}
+	return group.Wait()
+}
+
+func check(ctx context.Context, proxyURL string, r *recordRow) error {
+	result, err := checkVersion(ctx, proxyURL, r)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil {
+		// Leave the version unchecked, so it's checked again next time
+		log.Printf("leaving %s@%s unchecked on %s for now: %s", r.Module, r.Version, proxyURL, err)
+		return nil
+	}
+	if result.Status == checkUnequal {
+		log.Printf("%s served unexpected content for %s@%s: %s", proxyURL, r.Module, r.Version, result.Message.V)
+	}
+	return storeCheckResult(ctx, proxyURL, r.Module, r.Version, result)
+}
+
+// checkVersion returns the result of checking r against the proxy, or an
+// error if the check couldn't be completed because of a transient failure
+func checkVersion(ctx context.Context, proxyURL string, r *recordRow) (*checkResult, error) {
+	module, err := goproxy.MakeModulePath(r.Module)
+	if err != nil {
+		return failedResult(fmt.Errorf("invalid module path: %w", err)), nil
+	}
+	version, err := goproxy.MakeModuleVersion(r.Version)
+	if err != nil {
+		return failedResult(fmt.Errorf("invalid module version: %w", err)), nil
+	}
+	if _, err := FetchMod(ctx, proxyURL, module, version, r.GomodSHA256); httpclient.IsTransient(err) {
+		return nil, err
+	} else if err != nil {
+		return failedResult(err), nil
+	}
+	filename, err := FetchZip(ctx, proxyURL, module, version, r.SourceSHA256)
+	if httpclient.IsTransient(err) {
+		return nil, err
+	} else if err != nil {
+		return failedResult(err), nil
+	}
+	os.Remove(filename)
+	return &checkResult{Status: checkEqual}, nil
+}
+
+func failedResult(err error) *checkResult {
+	status := checkFailed
+	if mismatch := (*MismatchError)(nil); errors.As(err, &mismatch) {
+		status = checkUnequal
+	}
+	return &checkResult{Status: status, Message: sql.Null[string]{Valid: true, V: err.Error()}}
+}
+
+func storeCheckResult(ctx context.Context, proxyURL, module, version string, result *checkResult) error {
+	_, err := sourcespotter.DB.ExecContext(ctx, `
+		INSERT INTO proxy_check (proxy, module, version, status, message)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (proxy, module, version)
+		DO UPDATE SET
+			checked_at = EXCLUDED.checked_at,
+			status = EXCLUDED.status,
+			message = EXCLUDED.message
+	`, proxyURL, module, version, result.Status, result.Message)
+	if err != nil {
+		return fmt.Errorf("error storing %s check result for %s@%s from %s: %w", result.Status, module, version, proxyURL, err)
+	}
+	return nil
+}
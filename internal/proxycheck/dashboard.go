@@ -0,0 +1,138 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package proxycheck
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	basedashboard "software.sslmate.com/src/sourcespotter/internal/dashboard"
+	"src.agwa.name/go-dbutil"
+)
+
+type failureRow struct {
+	Proxy     string    `sql:"proxy"`
+	Module    string    `sql:"module"`
+	Version   string    `sql:"version"`
+	CheckedAt time.Time `sql:"checked_at"`
+	Status    string    `sql:"status"`
+	Message   string    `sql:"message"`
+}
+
+// UnrecordedVersion is a module version listed by a proxy which is missing from the sumdb
+type UnrecordedVersion struct {
+	Proxy    string    `sql:"proxy"`
+	Module   string    `sql:"module"`
+	Version  string    `sql:"version"`
+	ListedAt time.Time `sql:"listed_at"`
+}
+
+type dashboard struct {
+	Domain         string
+	Proxy          string
+	Proxies        []string
+	Watchlist      []string
+	SampleInterval int64
+	EqualCount     int64
+	Mismatches     []failureRow
+	Unrecorded     []UnrecordedVersion
+	Failures       []failureRow
+}
+
+// loadMismatches returns the versions for which a proxy served content that
+// disagrees with the sumdb.  If proxy is non-empty, only that proxy's
+// mismatches are returned.
+func loadMismatches(ctx context.Context, proxy string) ([]failureRow, error) {
+	var rows []failureRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `SELECT proxy, module, version, checked_at, status, coalesce(message,'') AS message FROM proxy_check WHERE status = 'unequal' AND ($1 = '' OR proxy = $1) ORDER BY checked_at DESC`, proxy); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// LoadUnrecorded returns the versions listed by a proxy which are still
+// missing from the sumdb, most recently listed first.  If proxy is non-empty,
+// only that proxy's versions are returned.  If module is non-empty, only
+// versions of that module are returned, or if module ends with a slash,
+// versions of every module with that prefix.
+func LoadUnrecorded(ctx context.Context, proxy string, module string) ([]UnrecordedVersion, error) {
+	var rows []UnrecordedVersion
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+		SELECT proxy, module, version, listed_at
+		FROM unrecorded_version u
+		WHERE ($1 = '' OR proxy = $1)
+		AND ($2 = '' OR module = $2 OR (right($2, 1) = '/' AND starts_with(module, $2)))
+		AND listed_at < $3
+		AND NOT EXISTS (SELECT 1 FROM record WHERE (record.module, record.version) = (u.module, u.version))
+		ORDER BY listed_at DESC
+	`, proxy, module, time.Now().Add(-unrecordedGracePeriod)); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func loadDashboard(ctx context.Context, proxy string) (*dashboard, error) {
+	dash := &dashboard{
+		Domain:         sourcespotter.Domain,
+		Proxy:          proxy,
+		Proxies:        allProxies(),
+		Watchlist:      Watchlist,
+		SampleInterval: SampleInterval,
+	}
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT count(*) FROM proxy_check WHERE status = 'equal' AND ($1 = '' OR proxy = $1)`, proxy).Scan(&dash.EqualCount); err != nil {
+		return nil, err
+	}
+	if mismatches, err := loadMismatches(ctx, proxy); err == nil {
+		dash.Mismatches = mismatches
+	} else {
+		return nil, err
+	}
+	if unrecorded, err := LoadUnrecorded(ctx, proxy, ""); err == nil {
+		dash.Unrecorded = unrecorded
+	} else {
+		return nil, err
+	}
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &dash.Failures, `SELECT proxy, module, version, checked_at, status, coalesce(message,'') AS message FROM proxy_check WHERE status = 'failed' AND ($1 = '' OR proxy = $1) ORDER BY checked_at DESC LIMIT 100`, proxy); err != nil {
+		return nil, err
+	}
+	return dash, nil
+}
+
+func ServeDashboard(w http.ResponseWriter, req *http.Request) {
+	dash, err := loadDashboard(req.Context(), req.URL.Query().Get("proxy"))
+	if err != nil {
+		log.Printf("error loading proxycheck dashboard: %s", err)
+		http.Error(w, "Internal Database Error", 500)
+		return
+	}
+	basedashboard.ServePage(w, req,
+		"Module Proxy Integrity - Source Spotter",
+		"Source Spotter verifies that module proxies serve the same content that is recorded in the Go Checksum Database.",
+		"proxycheck.html", dash)
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package proxycheck
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/atom"
+)
+
+func makeFeedURL(path string, proxy string) string {
+	u := "https://feeds.api." + sourcespotter.Domain + path
+	if proxy != "" {
+		u += "?" + url.Values{"proxy": {proxy}}.Encode()
+	}
+	return u
+}
+
+func feedTitle(title string, proxy string) string {
+	if proxy != "" {
+		title += " (" + proxy + ")"
+	}
+	return title
+}
+
+func ServeMismatchesAtom(w http.ResponseWriter, req *http.Request) {
+	proxy := req.URL.Query().Get("proxy")
+	rows, err := loadMismatches(req.Context(), proxy)
+	if err != nil {
+		log.Printf("error querying proxy check mismatches: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+
+	feedURL := makeFeedURL("/proxycheck/mismatches.atom", proxy)
+	feed := atom.Feed{
+		Xmlns:  "http://www.w3.org/2005/Atom",
+		ID:     feedURL,
+		Title:  feedTitle("Module Proxy Integrity Failures", proxy),
+		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
+		Links:  []atom.Link{{Rel: "self", Href: feedURL}},
+	}
+	if len(rows) > 0 {
+		feed.Updated = rows[0].CheckedAt.UTC().Format(time.RFC3339Nano)
+	} else {
+		feed.Updated = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	for _, row := range rows {
+		entry := atom.Entry{
+			Title:   fmt.Sprintf("%s served unexpected content for %s@%s", row.Proxy, row.Module, row.Version),
+			ID:      fmt.Sprintf("%s#%d-%s-%s@%s", feedURL, row.CheckedAt.UnixNano(), row.Proxy, row.Module, row.Version),
+			Updated: row.CheckedAt.UTC().Format(time.RFC3339Nano),
+			Content: atom.Content{Type: "text", Body: fmt.Sprintf("Proxy: %s\nModule: %s\nVersion: %s\n\n%s\n", row.Proxy, row.Module, row.Version, row.Message)},
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	writeFeed(w, &feed)
+}
+
+func ServeUnrecordedAtom(w http.ResponseWriter, req *http.Request) {
+	proxy := req.URL.Query().Get("proxy")
+	rows, err := LoadUnrecorded(req.Context(), proxy, "")
+	if err != nil {
+		log.Printf("error querying unrecorded versions: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+
+	feedURL := makeFeedURL("/proxycheck/unrecorded.atom", proxy)
+	feed := atom.Feed{
+		Xmlns:  "http://www.w3.org/2005/Atom",
+		ID:     feedURL,
+		Title:  feedTitle("Module Versions Missing from the Checksum Database", proxy),
+		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
+		Links:  []atom.Link{{Rel: "self", Href: feedURL}},
+	}
+	if len(rows) > 0 {
+		feed.Updated = rows[0].ListedAt.UTC().Format(time.RFC3339Nano)
+	} else {
+		feed.Updated = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	for _, row := range rows {
+		entry := atom.Entry{
+			Title:   fmt.Sprintf("%s lists %s@%s, which is not in the checksum database", row.Proxy, row.Module, row.Version),
+			ID:      fmt.Sprintf("%s#%s-%s@%s", feedURL, row.Proxy, row.Module, row.Version),
+			Updated: row.ListedAt.UTC().Format(time.RFC3339Nano),
+			Content: atom.Content{Type: "text", Body: fmt.Sprintf("Proxy: %s\nModule: %s\nVersion: %s\nFirst Listed: %s\n", row.Proxy, row.Module, row.Version, row.ListedAt.UTC().Format(time.RFC3339))},
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	writeFeed(w, &feed)
+}
+
+func writeFeed(w http.ResponseWriter, feed *atom.Feed) {
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		log.Printf("error encoding Atom feed: %s", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package proxycheck verifies that module proxies serve the content recorded in the sumdb
+package proxycheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/mod/sumdb/dirhash"
+	"software.sslmate.com/src/sourcespotter/internal/cooldown/goproxy"
+	"software.sslmate.com/src/sourcespotter/internal/httpclient"
+)
+
+// MismatchError is returned when a proxy serves a file whose hash disagrees with the sumdb
+type MismatchError struct {
+	URL      string
+	Hash     string
+	Expected string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("%s has unexpected hash %s (expected %s)", e.URL, e.Hash, e.Expected)
+}
+
+func formatHash1(sha256 []byte) string {
+	return "h1:" + base64.StdEncoding.EncodeToString(sha256)
+}
+
+// HashGoMod returns the h1: hash of a go.mod file, as it appears in go.sum
+func HashGoMod(data []byte) (string, error) {
+	return dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+}
+
+// FetchMod downloads the go.mod file for the given module version from the proxy
+// at proxyURL and verifies it against gomodSHA256.  If the hash doesn't match,
+// a *MismatchError is returned.
+func FetchMod(ctx context.Context, proxyURL string, module goproxy.ModulePath, version goproxy.ModuleVersion, gomodSHA256 []byte) ([]byte, error) {
+	url := goproxy.RequestURL(proxyURL, module, goproxy.ModRequest{Version: version})
+	data, err := httpclient.DownloadBytes(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading go.mod: %w", err)
+	}
+	hash, err := HashGoMod(data)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing go.mod from %s: %w", url, err)
+	}
+	if expected := formatHash1(gomodSHA256); hash != expected {
+		return nil, &MismatchError{URL: url, Hash: hash, Expected: expected}
+	}
+	return data, nil
+}
+
+// FetchZip downloads the zip file for the given module version from the proxy
+// at proxyURL to a temporary file and verifies it against sourceSHA256.  If the
+// hash doesn't match, a *MismatchError is returned.  The caller is responsible
+// for removing the temporary file.
+func FetchZip(ctx context.Context, proxyURL string, module goproxy.ModulePath, version goproxy.ModuleVersion, sourceSHA256 []byte) (string, error) {
+	url := goproxy.RequestURL(proxyURL, module, goproxy.ZipRequest{Version: version})
+	filename, err := httpclient.DownloadToTempFile(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("error downloading module zip: %w", err)
+	}
+	hash, err := dirhash.HashZip(filename, dirhash.Hash1)
+	if err != nil {
+		os.Remove(filename)
+		return "", fmt.Errorf("error hashing module zip from %s: %w", url, err)
+	}
+	if expected := formatHash1(sourceSHA256); hash != expected {
+		os.Remove(filename)
+		return "", &MismatchError{URL: url, Hash: hash, Expected: expected}
+	}
+	return filename, nil
+}
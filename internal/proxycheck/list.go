@@ -0,0 +1,159 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package proxycheck
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/cooldown/goproxy"
+	"software.sslmate.com/src/sourcespotter/internal/httpclient"
+	"src.agwa.name/go-dbutil"
+)
+
+// A version listed by a proxy isn't considered unrecorded until it has been
+// listed for this long, to give the sumdb and our ingester time to catch up
+const unrecordedGracePeriod = 1 * time.Hour
+
+// Proxies contains the base URLs of third-party module proxies (e.g.
+// "https://goproxy.cn") which are polled for every module in the Watchlist,
+// in addition to proxy.golang.org
+var Proxies []string
+
+// allProxies returns the base URLs of every proxy that is polled
+func allProxies() []string {
+	return append([]string{goproxy.DefaultProxy}, Proxies...)
+}
+
+func fetchList(ctx context.Context, proxyURL string, module goproxy.ModulePath) ([]string, error) {
+	data, err := httpclient.DownloadBytes(ctx, goproxy.RequestURL(proxyURL, module, goproxy.ListRequest{}))
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if version, _, _ := strings.Cut(strings.TrimSpace(line), " "); version != "" {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+// WatchedModules returns the paths of the modules in the Watchlist, and of
+// recorded modules which match its prefixes.  Modules listed exactly are
+// returned even if they have no records, so that versions which are missing
+// from the sumdb are detected.
+func WatchedModules(ctx context.Context) ([]string, error) {
+	modules, prefixes := splitWatchlist(Watchlist)
+	var matched []string
+	if len(prefixes) > 0 {
+		if err := dbutil.QueryAll(ctx, sourcespotter.DB, &matched, `SELECT DISTINCT module FROM record WHERE EXISTS (SELECT 1 FROM unnest($1::text[]) AS prefix WHERE starts_with(module, prefix))`, pq.Array(prefixes)); err != nil {
+			return nil, fmt.Errorf("error querying watched modules: %w", err)
+		}
+	}
+	return mergeModules(modules, matched), nil
+}
+
+// mergeModules returns the sorted union of two lists of module paths
+func mergeModules(a, b []string) []string {
+	merged := slices.Concat(a, b)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// PollAll polls proxy.golang.org and every third-party proxy for the versions
+// of every watched module, recording versions which are missing from the sumdb
+// and verifying the content of versions which are present
+func PollAll(ctx context.Context) error {
+	if len(Watchlist) == 0 {
+		return nil
+	}
+	modules, err := WatchedModules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, proxyURL := range allProxies() {
+		for _, module := range modules {
+			if err := pollModule(ctx, proxyURL, module); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func pollModule(ctx context.Context, proxyURL string, module string) error {
+	modulePath, err := goproxy.MakeModulePath(module)
+	if err != nil {
+		log.Printf("not polling %s for invalid module path %q: %s", proxyURL, module, err)
+		return nil
+	}
+	if err := recordUnrecordedVersions(ctx, proxyURL, modulePath); err != nil {
+		return err
+	}
+
+	var rows []recordRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+		SELECT DISTINCT ON (version) module, version, source_sha256, gomod_sha256
+		FROM record
+		WHERE module = $1
+		AND NOT EXISTS (SELECT 1 FROM proxy_check WHERE (proxy_check.proxy, proxy_check.module, proxy_check.version) = ($2, record.module, record.version) AND (proxy_check.status <> 'failed' OR proxy_check.checked_at >= $3))
+		ORDER BY version, db_id, position
+	`, module, proxyURL, time.Now().Add(-failedRecheckInterval)); err != nil {
+		return fmt.Errorf("error querying unchecked records of %s: %w", module, err)
+	}
+	return checkRecords(ctx, proxyURL, rows)
+}
+
+func recordUnrecordedVersions(ctx context.Context, proxyURL string, module goproxy.ModulePath) error {
+	listed, err := fetchList(ctx, proxyURL, module)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("error listing versions of %s on %s: %s", module, proxyURL, err)
+		return nil
+	}
+	var recorded []string
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &recorded, `SELECT DISTINCT version FROM record WHERE module = $1`, module.String()); err != nil {
+		return fmt.Errorf("error querying recorded versions of %s: %w", module, err)
+	}
+	for _, version := range listed {
+		if slices.Contains(recorded, version) {
+			continue
+		}
+		if _, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO unrecorded_version (proxy, module, version) VALUES ($1, $2, $3) ON CONFLICT (proxy, module, version) DO NOTHING`, proxyURL, module.String(), version); err != nil {
+			return fmt.Errorf("error inserting unrecorded_version row: %w", err)
+		}
+	}
+	return nil
+}
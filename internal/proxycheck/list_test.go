@@ -0,0 +1,71 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package proxycheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestFetchList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/example.com/!upper/@v/list" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write([]byte("v1.0.0\nv1.1.0 2024-01-01T00:00:00Z\n\n  v2.0.0-rc.1\n"))
+	}))
+	defer server.Close()
+
+	versions, err := fetchList(context.Background(), server.URL+"/", "example.com/Upper")
+	if err != nil {
+		t.Fatalf("fetchList: error: %s", err)
+	}
+	if want := []string{"v1.0.0", "v1.1.0", "v2.0.0-rc.1"}; !slices.Equal(versions, want) {
+		t.Errorf("fetchList = %q, want %q", versions, want)
+	}
+}
+
+func TestSplitWatchlist(t *testing.T) {
+	modules, prefixes := splitWatchlist([]string{"example.com/a", "example.com/b/", "golang.org/x/"})
+	if want := []string{"example.com/a"}; !slices.Equal(modules, want) {
+		t.Errorf("modules = %q, want %q", modules, want)
+	}
+	if want := []string{"example.com/b/", "golang.org/x/"}; !slices.Equal(prefixes, want) {
+		t.Errorf("prefixes = %q, want %q", prefixes, want)
+	}
+}
+
+func TestMergeModules(t *testing.T) {
+	// Exact watchlist entries are kept even when no record matched them
+	got := mergeModules([]string{"example.com/unrecorded", "example.com/b/c"}, []string{"example.com/b/c", "example.com/b/a"})
+	if want := []string{"example.com/b/a", "example.com/b/c", "example.com/unrecorded"}; !slices.Equal(got, want) {
+		t.Errorf("mergeModules = %q, want %q", got, want)
+	}
+}
@@ -56,6 +56,7 @@ type ingestState struct {
 	sths           []nextSTH
 	tx             *sql.Tx
 	copyStmt       *sql.Stmt
+	startPosition  uint64
 	pendingRecords int
 }
 
@@ -107,6 +108,7 @@ func (state *ingestState) begin(ctx context.Context) error {
 
 	state.tx = tx
 	state.copyStmt = stmt
+	state.startPosition = tree.Size()
 	tx = nil
 
 	return nil
@@ -123,6 +125,16 @@ func (state *ingestState) commit(ctx context.Context, verified bool) error {
 	if err := state.copyStmt.Close(); err != nil {
 		return fmt.Errorf("error closing COPY statement: %w", err)
 	}
+	if state.pendingRecords > 0 {
+		// Sumdbs are ingested concurrently, so number batches while holding a lock
+		// until commit, to ensure that ingest_id order is the order they become visible
+		if _, err := state.tx.ExecContext(ctx, `LOCK TABLE ingest IN EXCLUSIVE MODE`); err != nil {
+			return fmt.Errorf("error locking ingest table: %w", err)
+		}
+		if _, err := state.tx.ExecContext(ctx, `INSERT INTO ingest (ingest_id, db_id, start_position, end_position) SELECT coalesce(max(ingest_id), 0) + 1, $1, $2, $3 FROM ingest`, state.id, state.startPosition, state.tree.Size()); err != nil {
+			return fmt.Errorf("error inserting ingest row: %w", err)
+		}
+	}
 	if verified {
 		if err := dbutil.MustAffectRow(state.tx.ExecContext(ctx, `UPDATE db SET download_position = $1, verified_position = $1 WHERE db_id = $2`, dbutil.JSON(state.tree), state.id)); err != nil {
 			return fmt.Errorf("error updating download and verified position: %w", err)
@@ -34,51 +34,98 @@ import (
 
 	"software.sslmate.com/src/sourcespotter"
 	"software.sslmate.com/src/sourcespotter/internal/atom"
+	"src.agwa.name/go-dbutil"
 )
 
+const failuresPageSize = 100
+
+// failuresQuery returns inconsistent STHs and duplicate records, which are
+// the entries of the failures feed
+const failuresQuery = `
+	SELECT 'sth' AS kind, db.address AS sumdb, sth.tree_size, sth.root_hash, record.root_hash AS calculated_root_hash, 0 AS position, 0 AS previous_position, '' AS module, '' AS version, sth.observed_at
+	FROM sth
+	JOIN db USING (db_id)
+	JOIN record ON (record.db_id, record.position) = (sth.db_id, sth.tree_size-1)
+	WHERE sth.consistent = FALSE
+	UNION ALL
+	SELECT 'dup' AS kind, db.address AS sumdb, 0 AS tree_size, NULL AS root_hash, NULL AS calculated_root_hash, record.position, record.previous_position, record.module, record.version, record.observed_at
+	FROM record
+	JOIN db USING (db_id)
+	WHERE record.previous_position IS NOT NULL
+`
+
+type failureRow struct {
+	Kind               string    `sql:"kind"`
+	SumDB              string    `sql:"sumdb"`
+	TreeSize           uint64    `sql:"tree_size"`
+	RootHash           []byte    `sql:"root_hash"`
+	CalculatedRootHash []byte    `sql:"calculated_root_hash"`
+	Position           uint64    `sql:"position"`
+	PreviousPosition   uint64    `sql:"previous_position"`
+	Module             string    `sql:"module"`
+	Version            string    `sql:"version"`
+	ObservedAt         time.Time `sql:"observed_at"`
+}
+
 // ServeFailuresAtom publishes inconsistencies seen in checksum databases as an Atom feed.
 func ServeFailuresAtom(w http.ResponseWriter, req *http.Request) {
-	dashboard, err := LoadDashboard(req.Context())
+	page, err := atom.ParsePage(req)
 	if err != nil {
-		log.Printf("error loading dashboard: %s", err)
-		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	ctx := req.Context()
 	feedURL := "https://feeds.api." + sourcespotter.Domain + "/sumdb/failures.atom"
+	// An inconsistent STH only appears once the records it covers have been
+	// downloaded, so it can be inserted before existing entries
+	archive := atom.Archive{URL: feedURL, PageSize: failuresPageSize, Mutable: true}
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT count(*) FROM (`+failuresQuery+`) AS failure`).Scan(&archive.Total); err != nil {
+		log.Printf("error counting sumdb failures: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	start, end, ok := archive.Range(page)
+	if !ok {
+		http.Error(w, "Archive page not found", http.StatusNotFound)
+		return
+	}
+	var rows []failureRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, failuresQuery+` ORDER BY observed_at, kind, sumdb, tree_size, position OFFSET $1 LIMIT $2`, start, end-start); err != nil {
+		log.Printf("error querying sumdb failures: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+
 	feed := atom.Feed{
 		Xmlns:  "http://www.w3.org/2005/Atom",
 		ID:     feedURL,
 		Title:  "Checksum Database Audit Failures",
 		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
-		Link:   atom.Link{Rel: "self", Href: feedURL},
 	}
+	archive.Apply(&feed, page)
 	var latest time.Time
-	addTime := func(t time.Time) {
-		if t.After(latest) {
-			latest = t
-		}
-	}
-
-	for _, sth := range dashboard.InconsistentSTHs {
-		addTime(sth.ObservedAt)
-		entry := atom.Entry{
-			Title:   fmt.Sprintf("Inconsistent STH from %s", sth.SumDB),
-			ID:      fmt.Sprintf("%s#sth-%s-%d-%s", feedURL, sth.SumDB, sth.TreeSize, sth.RootHashString()),
-			Updated: sth.ObservedAt.UTC().Format(time.RFC3339Nano),
-			Content: atom.Content{Type: "text", Body: fmt.Sprintf("SumDB: %s\nTree Size: %d\nSTH Root Hash: %s\nExpected Root Hash: %s\n", sth.SumDB, sth.TreeSize, sth.RootHashString(), sth.CalculatedRootHashString())},
+	for _, row := range rows {
+		if row.ObservedAt.After(latest) {
+			latest = row.ObservedAt
 		}
-		feed.Entries = append(feed.Entries, entry)
-	}
-
-	for _, rec := range dashboard.DuplicateRecords {
-		addTime(rec.ObservedAt)
-		entry := atom.Entry{
-			Title:   fmt.Sprintf("Duplicate record in %s", rec.SumDB),
-			ID:      fmt.Sprintf("%s#dup-%s-%d", feedURL, rec.SumDB, rec.Position),
-			Updated: rec.ObservedAt.UTC().Format(time.RFC3339Nano),
-			Content: atom.Content{Type: "text", Body: fmt.Sprintf("SumDB: %s\nModule: %s\nVersion: %s\nPosition: %d\nPrevious Position: %d\n", rec.SumDB, rec.Module, rec.Version, rec.Position, rec.PreviousPosition)},
+		var entry atom.Entry
+		switch row.Kind {
+		case "sth":
+			sth := InconsistentSTH{SumDB: row.SumDB, TreeSize: row.TreeSize, RootHash: row.RootHash, CalculatedRootHash: row.CalculatedRootHash}
+			entry = atom.Entry{
+				Title:   fmt.Sprintf("Inconsistent STH from %s", sth.SumDB),
+				ID:      fmt.Sprintf("%s#sth-%s-%d-%s", feedURL, sth.SumDB, sth.TreeSize, sth.RootHashString()),
+				Content: atom.Content{Type: "text", Body: fmt.Sprintf("SumDB: %s\nTree Size: %d\nSTH Root Hash: %s\nExpected Root Hash: %s\n", sth.SumDB, sth.TreeSize, sth.RootHashString(), sth.CalculatedRootHashString())},
+			}
+		case "dup":
+			entry = atom.Entry{
+				Title:   fmt.Sprintf("Duplicate record in %s", row.SumDB),
+				ID:      fmt.Sprintf("%s#dup-%s-%d", feedURL, row.SumDB, row.Position),
+				Content: atom.Content{Type: "text", Body: fmt.Sprintf("SumDB: %s\nModule: %s\nVersion: %s\nPosition: %d\nPrevious Position: %d\n", row.SumDB, row.Module, row.Version, row.Position, row.PreviousPosition)},
+			}
 		}
+		entry.Updated = row.ObservedAt.UTC().Format(time.RFC3339Nano)
 		feed.Entries = append(feed.Entries, entry)
 	}
 
@@ -96,7 +143,7 @@ func ServeFailuresAtom(w http.ResponseWriter, req *http.Request) {
 
 	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Cache-Control", archive.CacheControl(page, 300))
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(http.StatusOK)
 	w.Write(data)
@@ -61,25 +61,49 @@ func ServeCountersCSV(w http.ResponseWriter, req *http.Request) {
 	cw.Flush()
 }
 
+const countersPageSize = 1000
+
+// countersAtomQuery returns each counter with the time it first appeared in the telemetry config
+const countersAtomQuery = `select tc.program,tc.type,tc.name,min(observed_at) as first_observed_at from telemetry_counter tc join record on record.module='golang.org/x/telemetry/config' and record.version=tc.version group by tc.program,tc.type,tc.name`
+
 func ServeCountersAtom(w http.ResponseWriter, req *http.Request) {
+	page, err := atom.ParsePage(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx := req.Context()
+	feedURL := "https://feeds.api." + sourcespotter.Domain + "/telemetry/counters.atom"
+	// A counter's first appearance can move earlier if an older config is
+	// processed late, so pages are not immutable
+	archive := atom.Archive{URL: feedURL, PageSize: countersPageSize, Mutable: true}
+	if err := sourcespotter.DB.QueryRowContext(ctx, `select count(*) from (`+countersAtomQuery+`) as counter`).Scan(&archive.Total); err != nil {
+		log.Printf("error counting telemetry counters: %s", err)
+		http.Error(w, "Internal Database Error", 500)
+		return
+	}
+	start, end, ok := archive.Range(page)
+	if !ok {
+		http.Error(w, "Archive page not found", http.StatusNotFound)
+		return
+	}
 	var rows []counterAtomRow
-	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `select tc.program,tc.type,tc.name,min(observed_at) as first_observed_at from telemetry_counter tc join record on record.module='golang.org/x/telemetry/config' and record.version=tc.version group by tc.program,tc.type,tc.name order by first_observed_at desc`); err != nil {
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, countersAtomQuery+` order by first_observed_at,program,type,name offset $1 limit $2`, start, end-start); err != nil {
 		log.Printf("error querying telemetry counters: %s", err)
 		http.Error(w, "Internal Database Error", 500)
 		return
 	}
 
-	feedURL := "https://feeds.api." + sourcespotter.Domain + "/telemetry/counters.atom"
 	feed := atom.Feed{
 		Xmlns:  "http://www.w3.org/2005/Atom",
 		ID:     feedURL,
 		Title:  "Go Telemetry Counters",
 		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
-		Link:   atom.Link{Rel: "self", Href: feedURL},
 	}
+	archive.Apply(&feed, page)
 	if len(rows) > 0 {
-		feed.Updated = rows[0].FirstObservedAt.UTC().Format(time.RFC3339Nano)
+		feed.Updated = rows[len(rows)-1].FirstObservedAt.UTC().Format(time.RFC3339Nano)
 	} else {
 		feed.Updated = time.Now().UTC().Format(time.RFC3339Nano)
 	}
@@ -103,7 +127,7 @@ func ServeCountersAtom(w http.ResponseWriter, req *http.Request) {
 
 	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.Header().Set("Cache-Control", "public, max-age=86400, must-revalidate")
+	w.Header().Set("Cache-Control", archive.CacheControl(page, 86400))
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(http.StatusOK)
 	w.Write(data)
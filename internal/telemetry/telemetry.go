@@ -28,15 +28,14 @@ package telemetry
 import (
 	"archive/zip"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
 
-	"golang.org/x/mod/sumdb/dirhash"
 	"software.sslmate.com/src/sourcespotter"
-	"software.sslmate.com/src/sourcespotter/internal/httpclient"
+	"software.sslmate.com/src/sourcespotter/internal/cooldown/goproxy"
+	"software.sslmate.com/src/sourcespotter/internal/proxycheck"
 	"src.agwa.name/go-dbutil"
 )
 
@@ -67,21 +66,15 @@ func recordConfigError(ctx context.Context, version string, configErr error) err
 }
 
 func refreshVersion(ctx context.Context, version string, sourceSHA256 []byte) error {
-	url := fmt.Sprintf("https://proxy.golang.org/golang.org/x/telemetry/config/@v/%s.zip", version)
-	filename, err := httpclient.DownloadToTempFile(ctx, url)
+	modVersion, err := goproxy.MakeModuleVersion(version)
 	if err != nil {
-		return recordConfigError(ctx, version, fmt.Errorf("error downloading module zip: %w", err))
+		return recordConfigError(ctx, version, fmt.Errorf("invalid module version: %w", err))
 	}
-	defer os.Remove(filename)
-
-	hash, err := dirhash.HashZip(filename, dirhash.Hash1)
+	filename, err := proxycheck.FetchZip(ctx, goproxy.DefaultProxy, "golang.org/x/telemetry/config", modVersion, sourceSHA256)
 	if err != nil {
-		return recordConfigError(ctx, version, fmt.Errorf("error hashing module zip: %w", err))
-	}
-	expected := "h1:" + base64.StdEncoding.EncodeToString(sourceSHA256)
-	if hash != expected {
-		return recordConfigError(ctx, version, fmt.Errorf("module zip has unexpected hash %s (expected %s)", hash, expected))
+		return recordConfigError(ctx, version, err)
 	}
+	defer os.Remove(filename)
 
 	cfg, err := readConfig(filename, version)
 	if err != nil {
@@ -44,8 +44,29 @@ import (
 	"src.agwa.name/go-dbutil"
 )
 
+const failuresPageSize = 100
+
 func ServeFailuresAtom(w http.ResponseWriter, req *http.Request) {
+	page, err := atom.ParsePage(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx := req.Context()
+	feedURL := "https://feeds.api." + sourcespotter.Domain + "/toolchain/failures.atom"
+	// Rebuilds replace a version's status, so failures can leave the feed
+	archive := atom.Archive{URL: feedURL, PageSize: failuresPageSize, Mutable: true}
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT count(*) FROM toolchain_build WHERE status NOT IN ('equal','skipped')`).Scan(&archive.Total); err != nil {
+		log.Printf("error counting toolchain failures: %s", err)
+		http.Error(w, "Internal Database Error", 500)
+		return
+	}
+	start, end, ok := archive.Range(page)
+	if !ok {
+		http.Error(w, "Archive page not found", http.StatusNotFound)
+		return
+	}
 	var rows []struct {
 		Version    string    `sql:"version"`
 		Status     string    `sql:"status"`
@@ -53,22 +74,21 @@ func ServeFailuresAtom(w http.ResponseWriter, req *http.Request) {
 		BuildID    []byte    `sql:"build_id"`
 		InsertedAt time.Time `sql:"inserted_at"`
 	}
-	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `SELECT version,status,coalesce(message,'') AS message,build_id,inserted_at FROM toolchain_build WHERE status NOT IN ('equal','skipped') ORDER BY inserted_at DESC`); err != nil {
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `SELECT version,status,coalesce(message,'') AS message,build_id,inserted_at FROM toolchain_build WHERE status NOT IN ('equal','skipped') ORDER BY inserted_at, version OFFSET $1 LIMIT $2`, start, end-start); err != nil {
 		log.Printf("error querying toolchain failures: %s", err)
 		http.Error(w, "Internal Database Error", 500)
 		return
 	}
 
-	feedURL := "https://feeds.api." + sourcespotter.Domain + "/toolchain/failures.atom"
 	feed := atom.Feed{
 		Xmlns:  "http://www.w3.org/2005/Atom",
 		ID:     feedURL,
 		Title:  "Go Toolchain Reproducibility Failures",
 		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
-		Link:   atom.Link{Rel: "self", Href: feedURL},
 	}
+	archive.Apply(&feed, page)
 	if len(rows) > 0 {
-		feed.Updated = rows[0].InsertedAt.UTC().Format(time.RFC3339Nano)
+		feed.Updated = rows[len(rows)-1].InsertedAt.UTC().Format(time.RFC3339Nano)
 	} else {
 		feed.Updated = time.Now().UTC().Format(time.RFC3339Nano)
 	}
@@ -118,7 +138,7 @@ func ServeFailuresAtom(w http.ResponseWriter, req *http.Request) {
 
 	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate") // not longer, even for archive pages, since zip URLs expire
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(http.StatusOK)
 	w.Write(data)
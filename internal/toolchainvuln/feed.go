@@ -51,7 +51,10 @@ func (v *unpublishedVulnRow) ReleaseSearchLink() string {
 	return fmt.Sprintf("https://groups.google.com/g/golang-announce/search?q=Go%%20%s%%20released", v.GoVersion)
 }
 
-const defaultMinAge time.Duration = 0
+const (
+	defaultMinAge       time.Duration = 0
+	unpublishedPageSize               = 100
+)
 
 // ServeUnpublishedAtom publishes an Atom feed of toolchain vulnerabilities
 // that have been released for more than a specified duration but are not yet published to vuln.go.dev.
@@ -69,20 +72,41 @@ func ServeUnpublishedAtom(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	page, err := atom.ParsePage(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	feedURL := "https://feeds.api." + sourcespotter.Domain + "/toolchainvuln/unpublished.atom?min_age=" + minAge.String()
+	// Vulnerabilities leave the feed once they are published
+	archive := atom.Archive{URL: feedURL, PageSize: unpublishedPageSize, Mutable: true}
+	releasedBefore := time.Now().Add(-minAge)
+	if err := sourcespotter.DB.QueryRowContext(ctx, `SELECT count(*) FROM toolchain_vuln WHERE goid IS NULL AND released_at < $1`, releasedBefore).Scan(&archive.Total); err != nil {
+		log.Printf("error counting unpublished toolchain vulns: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	start, end, ok := archive.Range(page)
+	if !ok {
+		http.Error(w, "Archive page not found", http.StatusNotFound)
+		return
+	}
+
 	var rows []unpublishedVulnRow
 	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows,
 		`SELECT goversion, cveid, released_at
 		 FROM toolchain_vuln
 		 WHERE goid IS NULL
 		   AND released_at < $1
-		 ORDER BY released_at ASC, goversion ASC, cveid`,
-		time.Now().Add(-minAge)); err != nil {
+		 ORDER BY released_at ASC, goversion ASC, cveid
+		 OFFSET $2 LIMIT $3`,
+		releasedBefore, start, end-start); err != nil {
 		log.Printf("error querying unpublished toolchain vulns: %s", err)
 		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
 		return
 	}
 
-	feedURL := "https://feeds.api." + sourcespotter.Domain + "/toolchainvuln/unpublished.atom?min_age=" + minAge.String()
 	feedTitle := "Unpublished Go Toolchain Vulnerabilities"
 	if minAge > 0 {
 		feedTitle += fmt.Sprintf(" (>%s)", minAge)
@@ -92,8 +116,8 @@ func ServeUnpublishedAtom(w http.ResponseWriter, req *http.Request) {
 		ID:     feedURL,
 		Title:  feedTitle,
 		Author: atom.Person{Name: "Source Spotter on " + sourcespotter.Domain},
-		Link:   atom.Link{Rel: "self", Href: feedURL},
 	}
+	archive.Apply(&feed, page)
 
 	var latest time.Time
 	for _, row := range rows {
@@ -141,7 +165,7 @@ func ServeUnpublishedAtom(w http.ResponseWriter, req *http.Request) {
 
 	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+	w.Header().Set("Cache-Control", archive.CacheControl(page, 300))
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(http.StatusOK)
 	w.Write(data)
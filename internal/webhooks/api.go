@@ -0,0 +1,282 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package webhooks
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/authorization"
+	"src.agwa.name/go-dbutil"
+)
+
+const (
+	maxDeliveries = 100
+
+	// Subscriptions need no authentication, so their number is limited, in
+	// total and per URL host
+	maxSubscriptions        = 10000
+	maxSubscriptionsPerHost = 100
+)
+
+// Subscription is the JSON request body accepted by ReceiveSubscription
+type Subscription struct {
+	Prefix   string // Module path, or module path prefix ending in a slash
+	Ed25519  []byte // If non-nil, versions authorized by this key are not delivered
+	Identity string // If non-empty, versions authorized by this CI identity (instead of a key) are not delivered
+	URL      string // HTTPS URL to POST events to
+}
+
+func (s *Subscription) validate() error {
+	path, isPrefix := strings.CutSuffix(s.Prefix, "/")
+	if err := module.CheckImportPath(path); err != nil {
+		return fmt.Errorf("invalid prefix: %w", err)
+	}
+	if isPrefix && !strings.Contains(path, "/") {
+		// A prefix like github.com/ would match a vast number of modules
+		return errors.New("prefix is too broad: it must have at least two path elements, such as example.com/owner/")
+	}
+	if s.Ed25519 != nil && len(s.Ed25519) != ed25519.PublicKeySize {
+		return errors.New("public key has wrong length")
+	}
+	if s.Identity != "" {
+		if s.Ed25519 != nil {
+			return errors.New("public key and identity cannot both be specified")
+		}
+		if _, err := authorization.ParseIdentity(s.Identity); err != nil {
+			return err
+		}
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return errors.New("URL must be an https URL without credentials")
+	}
+	return nil
+}
+
+// ReceiveSubscription creates a webhook subscription and responds with its
+// ID and signing secret, which is also used to manage the subscription
+func ReceiveSubscription(w http.ResponseWriter, req *http.Request) {
+	var sub Subscription
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, 100000))
+	if err := dec.Decode(&sub); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if dec.More() {
+		http.Error(w, "Invalid JSON: trailing data", http.StatusBadRequest)
+		return
+	}
+	if err := sub.validate(); err != nil {
+		http.Error(w, "Invalid subscription: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	u, _ := url.Parse(sub.URL)
+	host := strings.ToLower(u.Hostname())
+	var total, perHost int
+	if err := sourcespotter.DB.QueryRowContext(req.Context(), `SELECT count(*), count(*) FILTER (WHERE host = $1) FROM webhook_subscription`, host).Scan(&total, &perHost); err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	if total >= maxSubscriptions || perHost >= maxSubscriptionsPerHost {
+		http.Error(w, "Too many webhook subscriptions", http.StatusTooManyRequests)
+		return
+	}
+
+	secret := rand.Text()
+	var pubkey any // nil for NULL
+	if sub.Ed25519 != nil {
+		pubkey = sub.Ed25519
+	} else if sub.Identity != "" {
+		identity, _ := authorization.ParseIdentity(sub.Identity)
+		pubkey = identity.Key()
+	}
+	var id int64
+	if err := sourcespotter.DB.QueryRowContext(req.Context(), `INSERT INTO webhook_subscription (prefix, pubkey, url, host, secret) VALUES ($1, $2, $3, $4, $5) RETURNING subscription_id`, sub.Prefix, pubkey, sub.URL, host, secret).Scan(&id); err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		ID     int64
+		Secret string
+	}{id, secret})
+}
+
+// authenticate returns the ID of the subscription named by the request
+// path, after checking that the request carries its secret as a bearer token
+func authenticate(w http.ResponseWriter, req *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(req.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid subscription ID", http.StatusBadRequest)
+		return 0, false
+	}
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="webhooks"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	var secret string
+	if err := sourcespotter.DB.QueryRowContext(req.Context(), `SELECT secret FROM webhook_subscription WHERE subscription_id = $1`, id).Scan(&secret); errors.Is(err, sql.ErrNoRows) {
+		secret = ""
+	} else if err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return 0, false
+	}
+	if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="webhooks"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	return id, true
+}
+
+// DeleteSubscription deletes a webhook subscription and its delivery history
+func DeleteSubscription(w http.ResponseWriter, req *http.Request) {
+	id, ok := authenticate(w, req)
+	if !ok {
+		return
+	}
+	if _, err := sourcespotter.DB.ExecContext(req.Context(), `DELETE FROM webhook_subscription WHERE subscription_id = $1`, id); err != nil {
+		log.Print(err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type deliveryRow struct {
+	ID            int64     `sql:"delivery_id"`
+	Module        string    `sql:"module"`
+	Version       string    `sql:"version"`
+	ObservedAt    time.Time `sql:"observed_at"`
+	Status        string    `sql:"status"`
+	Attempts      int       `sql:"attempts"`
+	NextAttemptAt time.Time `sql:"next_attempt_at"`
+}
+
+// Delivery is a queued webhook delivery, as served by ServeDeliveries
+type Delivery struct {
+	ID            int64
+	Module        string
+	Version       string
+	ObservedAt    time.Time
+	Status        string // pending, delivered, failed, or authorized
+	Attempts      int
+	NextAttemptAt time.Time
+	History       []Attempt
+}
+
+// Attempt is an attempt to deliver a webhook
+type Attempt struct {
+	DeliveryID  int64     `sql:"delivery_id" json:"-"`
+	AttemptedAt time.Time `sql:"attempted_at"`
+	StatusCode  int       `sql:"status_code"` // 0 if no response was received
+	Error       string    `sql:"error"`
+}
+
+// ServeDeliveries serves, as JSON, the most recent deliveries of a
+// webhook subscription and the history of attempts to deliver them
+func ServeDeliveries(w http.ResponseWriter, req *http.Request) {
+	id, ok := authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	ctx := req.Context()
+	var rows []deliveryRow
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+		SELECT delivery_id, module, version, observed_at, status, attempts, next_attempt_at
+		FROM webhook_delivery
+		WHERE subscription_id = $1
+		ORDER BY delivery_id DESC
+		LIMIT $2
+	`, id, maxDeliveries); err != nil {
+		log.Printf("error loading webhook deliveries: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	var attempts []Attempt
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &attempts, `
+		SELECT a.delivery_id, a.attempted_at, coalesce(a.status_code, 0) AS status_code, coalesce(a.error, '') AS error
+		FROM webhook_attempt a
+		JOIN (SELECT delivery_id FROM webhook_delivery WHERE subscription_id = $1 ORDER BY delivery_id DESC LIMIT $2) d USING (delivery_id)
+		ORDER BY a.attempted_at
+	`, id, maxDeliveries); err != nil {
+		log.Printf("error loading webhook attempts: %s", err)
+		http.Error(w, "Internal Database Error", http.StatusInternalServerError)
+		return
+	}
+	deliveries := make([]Delivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = Delivery{
+			ID:            row.ID,
+			Module:        row.Module,
+			Version:       row.Version,
+			ObservedAt:    row.ObservedAt,
+			Status:        row.Status,
+			Attempts:      row.Attempts,
+			NextAttemptAt: row.NextAttemptAt,
+			History:       []Attempt{},
+		}
+		for _, a := range attempts {
+			if a.DeliveryID == row.ID {
+				deliveries[i].History = append(deliveries[i].History, a)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"software.sslmate.com/src/sourcespotter/internal/httpclient"
+)
+
+const (
+	maxAttempts = 12
+	maxDelay    = 24 * time.Hour
+)
+
+// Event is the JSON body of a webhook delivery
+type Event struct {
+	Subscription int64
+	Module       string
+	Version      string
+	SourceSHA256 []byte
+	GomodSHA256  []byte
+	ObservedAt   time.Time
+}
+
+var httpClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: httpclient.CheckPublicAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// retryDelay returns how long to wait after the given number of failed
+// attempts before trying again
+func retryDelay(attempts int) time.Duration {
+	delay := time.Minute << min(attempts, 11)
+	return min(delay, maxDelay)
+}
+
+// signature returns the value of the Sourcespotter-Signature header, which
+// is an HMAC-SHA256 over the timestamp, a period, and the body
+func signature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver POSTs event to url and returns the response status code, or 0 if
+// no response was received.  A non-2xx response is returned as an error.
+func deliver(ctx context.Context, client *http.Client, url string, secret string, deliveryID int64, event *Event, now time.Time) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", "sourcespotter-webhook")
+	req.Header.Set("Sourcespotter-Delivery", strconv.FormatInt(deliveryID, 10))
+	req.Header.Set("Sourcespotter-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("Sourcespotter-Signature", signature(secret, timestamp, body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.New(resp.Status)
+	}
+	return resp.StatusCode, nil
+}
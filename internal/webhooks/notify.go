@@ -0,0 +1,267 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package webhooks notifies subscribers of unauthorized module versions
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"software.sslmate.com/src/sourcespotter"
+	"software.sslmate.com/src/sourcespotter/internal/modules"
+	"src.agwa.name/go-dbutil"
+)
+
+const (
+	scanBatchSize = 10000
+	maxPending    = 1000
+
+	// Deliveries to different subscriptions are attempted concurrently, and
+	// only a few deliveries to each subscription are attempted per run, so
+	// that an unresponsive endpoint can only delay its own deliveries
+	maxConcurrentSubscriptions = 32
+	maxPendingPerSubscription  = 10
+
+	// gracePeriod gives maintainers time to authorize a version after it
+	// appears in the sumdb before subscribers are notified
+	gracePeriod = 10 * time.Minute
+)
+
+type subscription struct {
+	ID     int64  `sql:"subscription_id"`
+	Prefix string `sql:"prefix"`
+}
+
+type recordRow struct {
+	Position     int64     `sql:"position"`
+	Module       string    `sql:"module"`
+	Version      string    `sql:"version"`
+	SourceSHA256 []byte    `sql:"source_sha256"`
+	GomodSHA256  []byte    `sql:"gomod_sha256"`
+	ObservedAt   time.Time `sql:"observed_at"`
+}
+
+// covers reports whether a subscription for prefix applies to module
+func covers(prefix, module string) bool {
+	if prefix == module {
+		return true
+	}
+	return strings.HasSuffix(prefix, "/") && (strings.HasPrefix(module, prefix) || module+"/" == prefix)
+}
+
+// NotifyAll queues a delivery for every record which has appeared in the
+// sumdb since the last scan and is covered by a subscription, and then
+// attempts every delivery which is due.  Whether a version is authorized is
+// only checked when its delivery is attempted, after the grace period.  The
+// first time it runs for a sumdb, it starts from the sumdb's current position.
+func NotifyAll(ctx context.Context) error {
+	var subscriptions []subscription
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &subscriptions, `SELECT subscription_id, prefix FROM webhook_subscription`); err != nil {
+		return fmt.Errorf("error querying webhook subscriptions: %w", err)
+	}
+	var dbIDs []int32
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &dbIDs, `SELECT db_id FROM db ORDER BY db_id`); err != nil {
+		return fmt.Errorf("error querying sumdbs: %w", err)
+	}
+	for _, dbID := range dbIDs {
+		if err := scanDB(ctx, dbID, subscriptions); err != nil {
+			return err
+		}
+	}
+	return deliverPending(ctx)
+}
+
+func scanDB(ctx context.Context, dbID int32, subscriptions []subscription) error {
+	if _, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO webhook_scan (db_id, position) SELECT $1, coalesce(max(position), -1) FROM record WHERE db_id = $1 ON CONFLICT DO NOTHING`, dbID); err != nil {
+		return fmt.Errorf("error initializing webhook scan: %w", err)
+	}
+	for {
+		var rows []recordRow
+		if err := dbutil.QueryAll(ctx, sourcespotter.DB, &rows, `
+			SELECT r.position, r.module, r.version, r.source_sha256, r.gomod_sha256, r.observed_at
+			FROM record r
+			JOIN webhook_scan s ON s.db_id = r.db_id
+			WHERE r.db_id = $1 AND r.position > s.position
+			ORDER BY r.position
+			LIMIT $2
+		`, dbID, scanBatchSize); err != nil {
+			return fmt.Errorf("error querying new records: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		// Every version is delivered, including pre-release and pseudo-versions,
+		// since an unexpected one is as suspicious as any other
+		for _, row := range rows {
+			for _, sub := range subscriptions {
+				if !covers(sub.Prefix, row.Module) {
+					continue
+				}
+				if err := queueDelivery(ctx, &sub, &row); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := sourcespotter.DB.ExecContext(ctx, `UPDATE webhook_scan SET position = $2 WHERE db_id = $1`, dbID, rows[len(rows)-1].Position); err != nil {
+			return fmt.Errorf("error updating webhook scan position: %w", err)
+		}
+	}
+}
+
+func queueDelivery(ctx context.Context, sub *subscription, row *recordRow) error {
+	if _, err := sourcespotter.DB.ExecContext(ctx, `
+		INSERT INTO webhook_delivery (subscription_id, module, version, source_sha256, gomod_sha256, observed_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT DO NOTHING
+	`, sub.ID, row.Module, row.Version, row.SourceSHA256, row.GomodSHA256, row.ObservedAt, row.ObservedAt.Add(gracePeriod)); err != nil {
+		return fmt.Errorf("error queueing webhook delivery for %s@%s: %w", row.Module, row.Version, err)
+	}
+	return nil
+}
+
+type pendingDelivery struct {
+	ID             int64     `sql:"delivery_id"`
+	SubscriptionID int64     `sql:"subscription_id"`
+	URL            string    `sql:"url"`
+	Secret         string    `sql:"secret"`
+	Pubkey         []byte    `sql:"pubkey"`
+	Module         string    `sql:"module"`
+	Version        string    `sql:"version"`
+	SourceSHA256   []byte    `sql:"source_sha256"`
+	GomodSHA256    []byte    `sql:"gomod_sha256"`
+	ObservedAt     time.Time `sql:"observed_at"`
+	Attempts       int       `sql:"attempts"`
+}
+
+func deliverPending(ctx context.Context) error {
+	var pending []pendingDelivery
+	if err := dbutil.QueryAll(ctx, sourcespotter.DB, &pending, `
+		SELECT d.delivery_id, d.subscription_id, s.url, s.secret, s.pubkey, d.module, d.version, d.source_sha256, d.gomod_sha256, d.observed_at, d.attempts
+		FROM (
+			SELECT *, row_number() OVER (PARTITION BY subscription_id ORDER BY next_attempt_at, delivery_id) AS rank
+			FROM webhook_delivery
+			WHERE status = 'pending' AND next_attempt_at <= now()
+		) d
+		JOIN webhook_subscription s USING (subscription_id)
+		WHERE d.rank <= $2
+		ORDER BY d.next_attempt_at
+		LIMIT $1
+	`, maxPending, maxPendingPerSubscription); err != nil {
+		return fmt.Errorf("error querying pending webhook deliveries: %w", err)
+	}
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrentSubscriptions)
+	for _, deliveries := range groupBySubscription(pending) {
+		group.Go(func() error {
+			for i := range deliveries {
+				delivered, err := attemptDelivery(ctx, &deliveries[i])
+				if err != nil {
+					return err
+				}
+				if !delivered {
+					// Leave the rest for the next run, rather than waiting for a failing endpoint
+					break
+				}
+			}
+			return nil
+		})
+	}
+	return group.Wait()
+}
+
+// groupBySubscription groups deliveries by subscription, preserving their order
+func groupBySubscription(deliveries []pendingDelivery) [][]pendingDelivery {
+	var groups [][]pendingDelivery
+	index := make(map[int64]int)
+	for _, d := range deliveries {
+		i, ok := index[d.SubscriptionID]
+		if !ok {
+			i = len(groups)
+			index[d.SubscriptionID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], d)
+	}
+	return groups
+}
+
+// attemptDelivery attempts a delivery, and reports whether it succeeded or
+// is no longer needed
+func attemptDelivery(ctx context.Context, d *pendingDelivery) (bool, error) {
+	if d.Pubkey != nil {
+		// Authorization is only checked now, after the grace period, rather than
+		// when the delivery is queued, so the version may have been authorized since
+		authorized, err := modules.IsAuthorized(ctx, d.Pubkey, d.Module, d.Version, d.SourceSHA256, d.GomodSHA256)
+		if err != nil {
+			return false, fmt.Errorf("error checking authorization of %s@%s: %w", d.Module, d.Version, err)
+		}
+		if authorized {
+			if _, err := sourcespotter.DB.ExecContext(ctx, `UPDATE webhook_delivery SET status = 'authorized' WHERE delivery_id = $1`, d.ID); err != nil {
+				return false, fmt.Errorf("error updating webhook delivery %d: %w", d.ID, err)
+			}
+			return true, nil
+		}
+	}
+
+	event := &Event{
+		Subscription: d.SubscriptionID,
+		Module:       d.Module,
+		Version:      d.Version,
+		SourceSHA256: d.SourceSHA256,
+		GomodSHA256:  d.GomodSHA256,
+		ObservedAt:   d.ObservedAt,
+	}
+	statusCode, deliverErr := deliver(ctx, httpClient, d.URL, d.Secret, d.ID, event, time.Now())
+
+	var errorText any // nil for NULL
+	if deliverErr != nil {
+		errorText = deliverErr.Error()
+	}
+	var statusCodeArg any // nil for NULL
+	if statusCode != 0 {
+		statusCodeArg = statusCode
+	}
+	if _, err := sourcespotter.DB.ExecContext(ctx, `INSERT INTO webhook_attempt (delivery_id, status_code, error) VALUES ($1, $2, $3)`, d.ID, statusCodeArg, errorText); err != nil {
+		return false, fmt.Errorf("error storing webhook attempt for delivery %d: %w", d.ID, err)
+	}
+
+	attempts := d.Attempts + 1
+	status := "pending"
+	if deliverErr == nil {
+		status = "delivered"
+	} else if attempts >= maxAttempts {
+		status = "failed"
+		log.Printf("giving up on webhook delivery %d to %s: %s", d.ID, d.URL, deliverErr)
+	}
+	if _, err := sourcespotter.DB.ExecContext(ctx, `UPDATE webhook_delivery SET status = $2, attempts = $3, next_attempt_at = $4 WHERE delivery_id = $1`, d.ID, status, attempts, time.Now().Add(retryDelay(attempts))); err != nil {
+		return false, fmt.Errorf("error updating webhook delivery %d: %w", d.ID, err)
+	}
+	return deliverErr == nil, nil
+}
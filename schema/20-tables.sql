@@ -67,12 +67,24 @@ CREATE TABLE record (
 CREATE INDEX record_module ON record (module, version, db_id, position DESC);
 CREATE INDEX duplicate_module ON record (db_id) WHERE previous_position IS NOT NULL;
 
+-- Each committed batch of records, numbered in commit order, so that records
+-- can be listed in the order they became visible
+CREATE TABLE ingest (
+	ingest_id		bigint NOT NULL,
+	db_id			int NOT NULL REFERENCES db,
+	start_position		bigint NOT NULL,
+	end_position		bigint NOT NULL,
+	PRIMARY KEY (ingest_id)
+);
+CREATE INDEX ingest_position ON ingest (db_id, end_position);
+
 CREATE TABLE authorized_record (
         pubkey          bytea NOT NULL,
         module          text NOT NULL,
         version         text NOT NULL,
         source_sha256   bytea,
         gomod_sha256    bytea,
+        authorized_at   timestamptz NOT NULL DEFAULT statement_timestamp(),
 
         PRIMARY KEY (pubkey, module, version)
 );
@@ -144,4 +156,338 @@ CREATE TABLE toolchain_vuln (
 	PRIMARY KEY (goversion, cveid)
 );
 
+CREATE TABLE gomod (
+	module		text NOT NULL,
+	version		text NOT NULL,
+	inserted_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+	go_version	text,
+	error		text,
+
+	PRIMARY KEY (module, version)
+);
+CREATE INDEX gomod_errors ON gomod (inserted_at) WHERE error IS NOT NULL;
+
+CREATE TABLE gomod_require (
+	module		text NOT NULL,
+	version		text NOT NULL,
+	dep_module	text NOT NULL,
+	dep_version	text NOT NULL,
+	indirect	boolean NOT NULL,
+
+	FOREIGN KEY (module, version) REFERENCES gomod ON DELETE CASCADE
+);
+CREATE INDEX gomod_require_module ON gomod_require (module, version);
+CREATE INDEX gomod_require_dep ON gomod_require (dep_module, dep_version);
+
+CREATE TABLE gomod_replace (
+	module		text NOT NULL,
+	version		text NOT NULL,
+	old_module	text NOT NULL,
+	old_version	text NOT NULL, -- empty if all versions are replaced
+	new_module	text NOT NULL, -- module path or filesystem path
+	new_version	text NOT NULL, -- empty if new_module is a filesystem path
+
+	FOREIGN KEY (module, version) REFERENCES gomod ON DELETE CASCADE
+);
+CREATE INDEX gomod_replace_module ON gomod_replace (module, version);
+CREATE INDEX gomod_replace_new ON gomod_replace (new_module, new_version);
+
+CREATE TABLE gomod_retract (
+	module		text NOT NULL,
+	version		text NOT NULL,
+	low		text NOT NULL,
+	high		text NOT NULL,
+	rationale	text NOT NULL,
+
+	FOREIGN KEY (module, version) REFERENCES gomod ON DELETE CASCADE
+);
+CREATE INDEX gomod_retract_module ON gomod_retract (module, version);
+
+CREATE TYPE proxy_check_status AS ENUM (
+	'equal',
+	'unequal',
+	'failed'
+);
+
+CREATE TABLE proxy_check (
+	proxy		text NOT NULL, -- e.g. "https://proxy.golang.org"
+	module		text NOT NULL,
+	version		text NOT NULL,
+	checked_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+	status		proxy_check_status NOT NULL,
+	message		text,
+
+	PRIMARY KEY (proxy, module, version)
+);
+CREATE INDEX proxy_check_failures ON proxy_check (checked_at) WHERE status <> 'equal';
+
+CREATE TABLE unrecorded_version (
+	proxy		text NOT NULL, -- e.g. "https://goproxy.cn"
+	module		text NOT NULL,
+	version		text NOT NULL,
+	listed_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+
+	PRIMARY KEY (proxy, module, version)
+);
+CREATE INDEX unrecorded_version_listed_at ON unrecorded_version (listed_at);
+
+CREATE TABLE module_origin (
+	module		text NOT NULL,
+	version		text NOT NULL,
+	fetched_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+	vcs		text,
+	url		text,
+	ref		text, -- e.g. "refs/tags/v1.2.3"
+	hash		text, -- commit hash
+	subdir		text, -- module directory within repository
+	error		text,
+
+	PRIMARY KEY (module, version)
+);
+CREATE INDEX module_origin_errors ON module_origin (fetched_at) WHERE error IS NOT NULL;
+
+CREATE TYPE origin_alert_type AS ENUM (
+	'url_changed',
+	'tag_moved',
+	'tag_deleted'
+);
+
+CREATE TABLE origin_alert (
+	module		text NOT NULL,
+	version		text NOT NULL,
+	type		origin_alert_type NOT NULL,
+	old_value	text NOT NULL, -- previous URL, or recorded commit hash
+	new_value	text NOT NULL, -- new URL, or current commit hash ('' if tag deleted)
+	detected_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+
+	PRIMARY KEY (module, version, type, new_value)
+);
+CREATE INDEX origin_alert_detected_at ON origin_alert (detected_at);
+
+CREATE TYPE module_repro_status AS ENUM (
+	'equal',
+	'unequal',
+	'failed'
+);
+
+CREATE TABLE module_repro (
+	module		text NOT NULL,
+	version		text NOT NULL,
+	checked_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+	status		module_repro_status NOT NULL,
+	source_sha256	bytea, -- hash of reproduced zip file
+	message		text,
+
+	PRIMARY KEY (module, version)
+);
+CREATE INDEX module_repro_failures ON module_repro (checked_at) WHERE status <> 'equal';
+
+CREATE INDEX record_module_lower ON record (lower(module));
+
+CREATE TABLE module_path_scan (
+	db_id		int NOT NULL REFERENCES db,
+	position	bigint NOT NULL, -- last record position checked
+
+	PRIMARY KEY (db_id)
+);
+
+CREATE TYPE module_path_alert_kind AS ENUM (
+	'case_collision',
+	'homoglyph',
+	'edit_distance',
+	'major_version'
+);
+
+CREATE TABLE module_path_alert (
+	module		text NOT NULL,
+	kind		module_path_alert_kind NOT NULL,
+	similar_module	text NOT NULL,
+	detail		text NOT NULL,
+	detected_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+
+	PRIMARY KEY (module, kind, similar_module)
+);
+CREATE INDEX module_path_alert_detected_at ON module_path_alert (detected_at);
+
+CREATE TABLE activity_scan (
+	db_id		int NOT NULL REFERENCES db,
+	position	bigint NOT NULL, -- last record position scanned
+
+	PRIMARY KEY (db_id)
+);
+
+CREATE TABLE unusual_activity (
+	module		text NOT NULL,
+	version		text NOT NULL,
+	observed_at	timestamptz NOT NULL,
+	score		int NOT NULL,
+	signals		text[] NOT NULL, -- e.g. {dormant,burst}
+	detail		text NOT NULL,
+
+	PRIMARY KEY (module, version)
+);
+CREATE INDEX unusual_activity_observed_at ON unusual_activity (observed_at);
+
+CREATE TABLE authorization_nonce (
+	pubkey		bytea NOT NULL,
+	nonce		bytea NOT NULL,
+	received_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+
+	PRIMARY KEY (pubkey, nonce)
+);
+CREATE INDEX authorization_nonce_received_at ON authorization_nonce (received_at);
+
+CREATE TABLE authorization_submission (
+	submission_id	bigint NOT NULL, -- position in authorization_log
+	version		smallint NOT NULL, -- version of the submission format
+	pubkey		bytea NOT NULL, -- Ed25519 key, or the key which stands in for identity
+	signed_data	text NOT NULL, -- payload for versions 1 and 2, go.sum for version 0
+	signature	bytea, -- NULL for keyless submissions (version 2)
+	identity	text, -- ISSUER:REPOSITORY:WORKFLOW, for keyless submissions
+	identity_token	text, -- OIDC identity token, for keyless submissions
+	modules		text[] NOT NULL, -- module paths in the go.sum
+	received_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+
+	PRIMARY KEY (submission_id)
+);
+CREATE INDEX authorization_submission_pubkey ON authorization_submission (pubkey, submission_id);
+CREATE INDEX authorization_submission_modules ON authorization_submission USING gin (modules);
+
+CREATE TABLE authorization_log (
+	tree		jsonb NOT NULL DEFAULT jsonb_build_object() -- collapsed Merkle tree of authorization_submission entries
+);
+INSERT INTO authorization_log DEFAULT VALUES;
+
+CREATE TABLE authorization_log_hash (
+	level		smallint NOT NULL, -- tile level; each hash covers 256^level entries
+	position	bigint NOT NULL,
+	hash		bytea NOT NULL,
+
+	PRIMARY KEY (level, position)
+);
+
+CREATE TYPE verified_key_method AS ENUM (
+	'wellknown',
+	'dns',
+	'module'
+);
+
+CREATE TABLE verified_key (
+	prefix		text NOT NULL, -- module path, or prefix ending in a slash
+	pubkey		bytea NOT NULL,
+	method		verified_key_method NOT NULL,
+	version		text, -- module version containing the proof, for method 'module'
+	verified_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+	checked_at	timestamptz NOT NULL DEFAULT statement_timestamp(), -- when the proof last verified
+	expires_at	timestamptz NOT NULL, -- unless the proof verifies again before then
+
+	PRIMARY KEY (prefix, pubkey)
+);
+CREATE INDEX verified_key_pubkey ON verified_key (pubkey);
+CREATE INDEX verified_key_checked_at ON verified_key (checked_at);
+
+CREATE TABLE key_rotation (
+	old_pubkey	bytea NOT NULL,
+	new_pubkey	bytea NOT NULL,
+	statement	text NOT NULL, -- signed by old_pubkey
+	signature	bytea NOT NULL,
+	new_signature	bytea NOT NULL, -- over statement, by new_pubkey
+	log_index	bigint NOT NULL, -- position in authorization_log
+	received_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+
+	PRIMARY KEY (old_pubkey, new_pubkey),
+	UNIQUE (old_pubkey), -- a key can only be rotated once...
+	UNIQUE (new_pubkey), -- ...and only to a key which hasn't been rotated to before
+	UNIQUE (log_index)
+);
+
+CREATE TABLE key_revocation (
+	pubkey		bytea NOT NULL,
+	revoked_since	timestamptz NOT NULL, -- authorizations made at or after this time are ignored
+	signer		bytea NOT NULL, -- pubkey, or a key it was rotated to
+	statement	text NOT NULL,
+	signature	bytea NOT NULL,
+	log_index	bigint NOT NULL, -- position in authorization_log
+	received_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+
+	PRIMARY KEY (pubkey, revoked_since, signer),
+	UNIQUE (log_index)
+);
+
+CREATE TABLE authorization_policy (
+	policy_id	text NOT NULL, -- hex-encoded SHA-256 hash of policy
+	module		text NOT NULL, -- module path, or module path prefix ending in a slash
+	threshold	integer NOT NULL,
+	keys		bytea[] NOT NULL,
+	policy		text NOT NULL,
+	log_index	bigint NOT NULL, -- position in authorization_log
+	received_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+
+	PRIMARY KEY (policy_id),
+	UNIQUE (log_index)
+);
+CREATE INDEX authorization_policy_module ON authorization_policy (module);
+
+CREATE TABLE authorization_policy_signature (
+	policy_id	text NOT NULL REFERENCES authorization_policy,
+	pubkey		bytea NOT NULL,
+	signature	bytea NOT NULL,
+
+	PRIMARY KEY (policy_id, pubkey)
+);
+
+CREATE TABLE webhook_subscription (
+	subscription_id	bigserial NOT NULL,
+	prefix		text NOT NULL, -- module path, or module path prefix ending in a slash
+	pubkey		bytea, -- if non-NULL, versions authorized by this key are not delivered
+	url		text NOT NULL,
+	host		text NOT NULL, -- lowercased host of url, for limiting subscriptions per host
+	secret		text NOT NULL, -- HMAC key for signing deliveries, and bearer token for managing the subscription
+	created_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+
+	PRIMARY KEY (subscription_id)
+);
+
+CREATE INDEX webhook_subscription_host ON webhook_subscription (host);
+
+CREATE TABLE webhook_scan (
+	db_id		int NOT NULL REFERENCES db,
+	position	bigint NOT NULL, -- last record position scanned
+
+	PRIMARY KEY (db_id)
+);
+
+CREATE TYPE webhook_delivery_status AS ENUM (
+	'pending',
+	'delivered',
+	'failed',
+	'authorized' -- version was authorized before the delivery was attempted
+);
+
+CREATE TABLE webhook_delivery (
+	delivery_id	bigserial NOT NULL,
+	subscription_id	bigint NOT NULL REFERENCES webhook_subscription ON DELETE CASCADE,
+	module		text NOT NULL,
+	version		text NOT NULL,
+	source_sha256	bytea NOT NULL,
+	gomod_sha256	bytea NOT NULL,
+	observed_at	timestamptz NOT NULL,
+	status		webhook_delivery_status NOT NULL DEFAULT 'pending',
+	attempts	int NOT NULL DEFAULT 0,
+	next_attempt_at	timestamptz NOT NULL,
+
+	PRIMARY KEY (delivery_id),
+	UNIQUE (subscription_id, module, version)
+);
+CREATE INDEX webhook_delivery_pending ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE webhook_attempt (
+	delivery_id	bigint NOT NULL REFERENCES webhook_delivery ON DELETE CASCADE,
+	attempted_at	timestamptz NOT NULL DEFAULT statement_timestamp(),
+	status_code	int, -- NULL if no response was received
+	error		text, -- NULL if the delivery succeeded
+
+	PRIMARY KEY (delivery_id, attempted_at)
+);
+
 COMMIT;
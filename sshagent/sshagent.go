@@ -0,0 +1,134 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package sshagent signs with Ed25519 keys held by an SSH agent
+package sshagent
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Dial connects to the SSH agent listening on $SSH_AUTH_SOCK
+func Dial() (agent.Agent, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, errors.New("$SSH_AUTH_SOCK is not set: start ssh-agent or set $SSH_AUTH_SOCK to the agent's socket")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to SSH agent: %w", err)
+	}
+	return agent.NewClient(conn), nil
+}
+
+// ParsePublicKey parses an Ed25519 public key, either in authorized_keys
+// format (as found in a .pub file) or as plain base64
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	s = strings.TrimSpace(s)
+	if raw, err := base64.StdEncoding.DecodeString(s); err == nil && len(raw) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(raw), nil
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH public key: %w", err)
+	}
+	return ed25519Key(key)
+}
+
+func ed25519Key(key ssh.PublicKey) (ed25519.PublicKey, error) {
+	if key.Type() != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("%s keys are not supported: only %s keys can be used", key.Type(), ssh.KeyAlgoED25519)
+	}
+	cryptoKey, ok := key.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, errors.New("unable to extract Ed25519 public key")
+	}
+	pubkey, ok := cryptoKey.CryptoPublicKey().(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("unable to extract Ed25519 public key")
+	}
+	return pubkey, nil
+}
+
+// Signer is a crypto.Signer for an Ed25519 key held by an SSH agent.  Its
+// signatures are raw Ed25519 signatures, identical to those made by
+// ed25519.PrivateKey.
+type Signer struct {
+	agent  agent.Agent
+	key    ssh.PublicKey
+	pubkey ed25519.PublicKey
+}
+
+// NewSigner returns a Signer for the key held by a whose public key is
+// pubkey.  It returns an error if a doesn't hold the key.
+func NewSigner(a agent.Agent, pubkey ed25519.PublicKey) (*Signer, error) {
+	key, err := ssh.NewPublicKey(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := a.List()
+	if err != nil {
+		return nil, fmt.Errorf("error listing SSH agent keys: %w", err)
+	}
+	for _, agentKey := range keys {
+		if agentKey.Type() == ssh.KeyAlgoED25519 && bytes.Equal(agentKey.Marshal(), key.Marshal()) {
+			return &Signer{agent: a, key: key, pubkey: pubkey}, nil
+		}
+	}
+	return nil, fmt.Errorf("the SSH agent does not hold the key %s (add it with ssh-add)", strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))))
+}
+
+// Public returns the signer's ed25519.PublicKey
+func (s *Signer) Public() crypto.PublicKey {
+	return s.pubkey
+}
+
+// Sign asks the agent to sign message.  As with ed25519.PrivateKey, the
+// message is not hashed, so opts.HashFunc() must return zero.
+func (s *Signer) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, errors.New("sshagent: cannot sign hashed message")
+	}
+	sig, err := s.agent.Sign(s.key, message)
+	if err != nil {
+		return nil, fmt.Errorf("SSH agent failed to sign: %w", err)
+	}
+	if sig.Format != ssh.KeyAlgoED25519 || !ed25519.Verify(s.pubkey, message, sig.Blob) {
+		return nil, errors.New("SSH agent returned an invalid signature")
+	}
+	return sig.Blob, nil
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package sshagent
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"software.sslmate.com/src/sourcespotter/authorization"
+)
+
+// serveKeyring serves an in-process agent holding keys on a Unix socket,
+// and points $SSH_AUTH_SOCK at it
+func serveKeyring(t *testing.T, keys ...any) {
+	keyring := agent.NewKeyring()
+	for _, key := range keys {
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+}
+
+func TestSigner(t *testing.T) {
+	const domain = "sourcespotter.example"
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	serveKeyring(t, ecdsaKey, priv)
+
+	a, err := Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSigner(a, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSigner(a, otherPub); err == nil {
+		t.Errorf("NewSigner succeeded for a key the agent doesn't hold")
+	}
+
+	payload, err := authorization.NewPayload(domain, "example.com/mod v1.0.0 h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	submission, err := authorization.SignWith(signer, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(ed25519.PublicKey(submission.Ed25519)) {
+		t.Errorf("submission has wrong public key")
+	}
+	// The agent's signature is the same as one made with the private key directly
+	if want := authorization.Sign(priv, payload); string(submission.Signature) != string(want.Signature) {
+		t.Errorf("agent signature differs from ed25519.Sign")
+	}
+	if _, err := submission.Verify(domain, time.Now()); err != nil {
+		t.Errorf("Verify returned error: %s", err)
+	}
+
+	revocation := &authorization.Revocation{Domain: domain, Timestamp: time.Now().UTC().Truncate(time.Second), Key: pub, Since: time.Now().UTC().Truncate(time.Second)}
+	statement, err := authorization.SignStatementWith(signer, revocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := statement.Verify(domain, time.Now()); err != nil {
+		t.Errorf("statement Verify returned error: %s", err)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	sshKey, _ := ssh.NewPublicKey(pub)
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecdsaSSHKey, _ := ssh.NewPublicKey(&ecdsaKey.PublicKey)
+
+	tests := []struct {
+		in string
+		ok bool
+	}{
+		{string(ssh.MarshalAuthorizedKey(sshKey)), true},
+		{strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshKey))) + " user@host\n", true},
+		{base64.StdEncoding.EncodeToString(pub), true},
+		{string(ssh.MarshalAuthorizedKey(ecdsaSSHKey)), false},
+		{"ssh-ed25519 AAAA", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		key, err := ParsePublicKey(test.in)
+		if (err == nil) != test.ok {
+			t.Errorf("ParsePublicKey(%q) returned error %v, want ok=%v", test.in, err, test.ok)
+		} else if err == nil && !key.Equal(pub) {
+			t.Errorf("ParsePublicKey(%q) returned wrong key", test.in)
+		}
+	}
+}
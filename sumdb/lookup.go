@@ -0,0 +1,222 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package sumdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+)
+
+// DefaultLookupKey is the verifier key of sum.golang.org
+const DefaultLookupKey = "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8"
+
+// ErrNotFound is returned by Lookup when the checksum database has no
+// record of a module version
+var ErrNotFound = errors.New("not found in checksum database")
+
+// ParseGOSUMDB parses the value of $GOSUMDB the way the go command does,
+// returning the verifier key and URL of the checksum database.  The value is
+// a key, or the name sum.golang.org, optionally followed by the URL of the
+// database.
+func ParseGOSUMDB(value string) (string, string, error) {
+	if strings.TrimSpace(value) == "sum.golang.google.cn" {
+		value = "sum.golang.org https://sum.golang.google.cn"
+	}
+	fields := strings.Fields(value)
+	switch {
+	case len(fields) == 0:
+		fields = []string{"sum.golang.org"}
+	case fields[0] == "off":
+		return "", "", errors.New("GOSUMDB is off")
+	case len(fields) > 2:
+		return "", "", fmt.Errorf("invalid GOSUMDB: too many fields")
+	}
+	if fields[0] == "sum.golang.org" {
+		fields[0] = DefaultLookupKey
+	}
+	verifier, err := note.NewVerifier(fields[0])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid GOSUMDB: %w", err)
+	}
+	url := "https://" + verifier.Name()
+	if len(fields) == 2 {
+		url = strings.TrimSuffix(fields[1], "/")
+	}
+	return fields[0], url, nil
+}
+
+// LookupClient looks up module versions in a checksum database, verifying
+// that every record it returns is included in the database's signed tree
+type LookupClient struct {
+	client *sumdb.Client
+	ops    *lookupOps
+}
+
+// NewLookupClient returns a client for the checksum database with the given
+// verifier key, served at url.  Note that looking up a module version
+// causes the checksum database to record it, if it hasn't already.
+func NewLookupClient(key, url string) (*LookupClient, error) {
+	if _, err := note.NewVerifier(key); err != nil {
+		return nil, fmt.Errorf("invalid checksum database key: %w", err)
+	}
+	ops := &lookupOps{
+		key:      key,
+		url:      strings.TrimSuffix(url, "/"),
+		config:   make(map[string][]byte),
+		cache:    make(map[string][]byte),
+		notFound: make(map[string]bool),
+	}
+	return &LookupClient{client: sumdb.NewClient(ops), ops: ops}, nil
+}
+
+// Lookup returns the go.sum lines which the checksum database has recorded
+// for a module version, or ErrNotFound
+func (c *LookupClient) Lookup(modulePath, version string) (string, error) {
+	var goSum strings.Builder
+	for _, vers := range []string{version, version + "/go.mod"} {
+		lines, err := c.client.Lookup(modulePath, vers)
+		if message := c.ops.securityError(); message != "" {
+			return "", fmt.Errorf("checksum database misbehaved: %s", message)
+		}
+		if err != nil {
+			if c.ops.wasNotFound(modulePath, version) {
+				return "", ErrNotFound
+			}
+			return "", err
+		}
+		for _, line := range lines {
+			goSum.WriteString(line + "\n")
+		}
+	}
+	return goSum.String(), nil
+}
+
+// lookupOps implements sumdb.ClientOps, with configuration and cache kept
+// in memory
+type lookupOps struct {
+	key string
+	url string
+
+	mu       sync.Mutex
+	config   map[string][]byte
+	cache    map[string][]byte
+	notFound map[string]bool // remote paths that returned 404 or 410
+	security string
+}
+
+func (ops *lookupOps) ReadRemote(path string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, ops.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "sourcespotter")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from %s: %w", req.URL, err)
+	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		ops.mu.Lock()
+		ops.notFound[path] = true
+		ops.mu.Unlock()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s: %s", req.URL, resp.Status, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
+
+func (ops *lookupOps) wasNotFound(modulePath, version string) bool {
+	escapedPath, err1 := module.EscapePath(modulePath)
+	escapedVersion, err2 := module.EscapeVersion(version)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	return ops.notFound["/lookup/"+escapedPath+"@"+escapedVersion]
+}
+
+func (ops *lookupOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return []byte(ops.key), nil
+	}
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	return ops.config[file], nil
+}
+
+func (ops *lookupOps) WriteConfig(file string, old, new []byte) error {
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	if !bytes.Equal(ops.config[file], old) {
+		return sumdb.ErrWriteConflict
+	}
+	ops.config[file] = new
+	return nil
+}
+
+func (ops *lookupOps) ReadCache(file string) ([]byte, error) {
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	if data, ok := ops.cache[file]; ok {
+		return data, nil
+	}
+	return nil, errors.New("not cached")
+}
+
+func (ops *lookupOps) WriteCache(file string, data []byte) {
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	ops.cache[file] = data
+}
+
+func (ops *lookupOps) Log(msg string) {}
+
+func (ops *lookupOps) SecurityError(msg string) {
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	ops.security = msg
+}
+
+func (ops *lookupOps) securityError() string {
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	return ops.security
+}
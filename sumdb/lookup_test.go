@@ -0,0 +1,113 @@
+// Copyright (C) 2026 Opsmate, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package sumdb
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+)
+
+func TestLookupClient(t *testing.T) {
+	const goSum = "example.com/mod v1.0.0 h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=\nexample.com/mod v1.0.0/go.mod h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n"
+	signer, verifier, err := note.GenerateKey(rand.Reader, "sum.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testServer := sumdb.NewTestServer(signer, func(path, vers string) ([]byte, error) {
+		if path == "example.com/mod" && vers == "v1.0.0" {
+			return []byte(goSum), nil
+		}
+		return nil, os.ErrNotExist
+	})
+	server := httptest.NewServer(sumdb.NewServer(testServer))
+	defer server.Close()
+
+	client, err := NewLookupClient(verifier, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.Lookup("example.com/mod", "v1.0.0")
+	if err != nil {
+		t.Fatalf("Lookup returned error: %s", err)
+	}
+	if got != goSum {
+		t.Errorf("Lookup returned %q, want %q", got, goSum)
+	}
+	if _, err := client.Lookup("example.com/mod", "v2.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Lookup of missing version returned %v, want ErrNotFound", err)
+	}
+
+	_, otherVerifier, _ := note.GenerateKey(rand.Reader, "sum.example")
+	otherClient, err := NewLookupClient(otherVerifier, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := otherClient.Lookup("example.com/mod", "v1.0.0"); err == nil {
+		t.Errorf("Lookup succeeded with the wrong key")
+	}
+}
+
+func TestParseGOSUMDB(t *testing.T) {
+	_, key, err := note.GenerateKey(rand.Reader, "sum.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		value   string
+		wantKey string
+		wantURL string
+	}{
+		{"", DefaultLookupKey, "https://sum.golang.org"},
+		{"sum.golang.org", DefaultLookupKey, "https://sum.golang.org"},
+		{"sum.golang.google.cn", DefaultLookupKey, "https://sum.golang.google.cn"},
+		{key, key, "https://sum.example"},
+		{key + " https://mirror.example/sumdb/", key, "https://mirror.example/sumdb"},
+		{"sum.golang.org https://mirror.example/sumdb", DefaultLookupKey, "https://mirror.example/sumdb"},
+		{DefaultLookupKey, DefaultLookupKey, "https://sum.golang.org"},
+		{"off", "", ""},
+		{"sum.example", "", ""},
+		{"sum.golang.google.cn https://mirror.example", "", ""},
+		{"sum.golang.org https://mirror.example extra", "", ""},
+	}
+	for _, test := range tests {
+		key, url, err := ParseGOSUMDB(test.value)
+		if test.wantKey == "" {
+			if err == nil {
+				t.Errorf("ParseGOSUMDB(%q) succeeded, want error", test.value)
+			}
+		} else if err != nil {
+			t.Errorf("ParseGOSUMDB(%q) returned error: %s", test.value, err)
+		} else if key != test.wantKey || url != test.wantURL {
+			t.Errorf("ParseGOSUMDB(%q) = %q, %q, want %q, %q", test.value, key, url, test.wantKey, test.wantURL)
+		}
+	}
+}